@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/cli"
+	"github.com/palaforcade/zchat/internal/config"
+	"github.com/palaforcade/zchat/internal/shellinit"
+)
+
+// commandLine defines zchat's commands and flags, for parsing, the help and completion
+var commandLine = cli.Spec{
+	Name:  "zchat",
+	Args:  "[--] <natural language query>",
+	Flags: slices.Concat(globalFlags, settingFlags()),
+	Commands: []cli.Command{
+		{Name: "ask", Args: "<query>", Usage: "turn a request into a command and run it, like a bare query"},
+		{Name: "explain", Args: "'<command>'", Usage: "explain a command without running it", Flags: []cli.Flag{
+			{Name: "json", Usage: "print only the explanation, as JSON"},
+		}},
+		{Name: "fix", Usage: "ask the model to correct the last failed command"},
+		{Name: "history", Args: "[search <text> | rerun <id>]", Usage: "list, search or re-run past commands", Subcommands: []string{"search", "rerun"}},
+		{Name: "save", Args: "<name> [param=value ...]", Usage: "save the last successful command as a snippet"},
+		{Name: "run", Args: "[<name> [param=value ...] [request]]", Usage: "run a saved snippet, or list them"},
+		{Name: "config", Args: "init|show|get|set|validate", Usage: "set up, inspect or change the configuration", Subcommands: configCommands},
+		{Name: "init", Args: "<zsh|bash|fish>", Usage: "print the shell widget, bound to Alt-z", Subcommands: shellinit.Shells},
+		{Name: "completion", Args: "<bash|zsh|fish>", Usage: "print the shell completion script", Subcommands: cli.Shells},
+	},
+}
+
+// globalFlags are accepted before the query or command, and after the command name. Flags
+// named after a setting override it; the others are handled by parseOptions.
+var globalFlags = []cli.Flag{
+	{Name: "provider", Arg: "name", Values: []string{"ollama", "anthropic"}, Usage: "ask this provider: ollama or anthropic"},
+	{Name: "model", Arg: "name", Usage: "ask this model"},
+	{Name: "timeout", Arg: "duration", Usage: "bound each model request and command, like 1m; 0 for none"},
+	{Name: "profile", Arg: "name", Usage: "use a config profile (also ZCHAT_PROFILE)"},
+	{Name: "output", Arg: "format", Values: []string{"text", "json"}, Usage: "json writes the run as one JSON object, for editors and tools"},
+	{Name: "yes", Short: "y", Usage: "run commands without asking, except dangerous ones"},
+	{Name: "dry-run", Usage: "show the command without running it"},
+	{Name: "no-exec", Usage: "same as --dry-run", Hidden: true},
+	{Name: "approve", Arg: "policy", Values: []string{config.ApprovePrompt, config.ApproveReadOnly, config.ApproveAll, config.ApproveNever}, Usage: "when commands run without asking: prompt, readonly, all or never"},
+	{Name: "explain", Usage: "explain the command before asking to run it"},
+	{Name: "plan", Usage: "break the request into steps and run them one by one"},
+	{Name: "print", Usage: "print the command only, for shell integrations"},
+	{Name: "interactive", Short: "i", Usage: "start an interactive session"},
+	{Name: "color", Arg: "when", Default: config.ColorAlways, Values: []string{config.ColorAuto, config.ColorAlways, config.ColorNever}, Usage: "highlight commands: auto (on a terminal, unless NO_COLOR is set), always or never"},
+	{Name: "no-cache", Usage: "ask the model even if the answer is cached"},
+	{Name: "verbose", Short: "v", Usage: "list what was masked before sending"},
+	{Name: "help", Short: "h", Usage: "show the help"},
+}
+
+// settingFlags returns a flag for each setting without one in globalFlags, like
+// --max-fix-rounds, so that every setting but secrets and profiles can be overridden for a single run
+func settingFlags() []cli.Flag {
+	var flags []cli.Flag
+	for _, key := range config.Keys() {
+		name := strings.ReplaceAll(key, "_", "-")
+		if !config.Overridable(key) || slices.ContainsFunc(globalFlags, func(f cli.Flag) bool { return f.Name == name }) {
+			continue
+		}
+		flag := cli.Flag{Name: name, Arg: "value", Usage: "set " + key + " for this run", Hidden: true}
+		if config.IsSwitch(key) {
+			flag.Arg = ""
+		}
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// options are the flags that precede the query or subcommand
+type options struct {
+	plan        bool
+	interactive bool
+	print       bool   // write only the command to stdout, without confirming or running it
+	output      string // "text" or "json"
+	explain     bool   // explain the generated command before asking to run it
+	json        bool   // zchat explain --json: print only the explanation
+	profile     string // config profile to use instead of the one picked by ZCHAT_PROFILE or the rules
+	help        bool
+	settings    []setting // config overrides, in the order given
+}
+
+// setting is a config override given on the command line
+type setting struct {
+	key   string
+	value string
+	flag  string // the flag that set it, for errors and `zchat config show`
+}
+
+// parseOptions turns the parsed flags into options
+func parseOptions(inv *cli.Invocation) (options, error) {
+	var opts options
+	for _, f := range inv.Flags {
+		flag := "--" + f.Name
+		var on bool
+		var err error
+		switch f.Name {
+		case "output":
+			opts.output = f.Value
+		case "profile":
+			opts.profile = f.Value
+		case "explain":
+			opts.explain, err = switchOn(f)
+		case "json":
+			opts.json, err = switchOn(f)
+		case "plan":
+			opts.plan, err = switchOn(f)
+		case "print":
+			opts.print, err = switchOn(f)
+		case "interactive":
+			opts.interactive, err = switchOn(f)
+		case "help":
+			opts.help, err = switchOn(f)
+		case "yes":
+			if on, err = switchOn(f); on {
+				opts.settings = append(opts.settings, setting{"approve", config.ApproveAll, flag})
+			}
+		case "dry-run", "no-exec":
+			if on, err = switchOn(f); on {
+				opts.settings = append(opts.settings, setting{"approve", config.ApproveNever, flag})
+			}
+		case "no-cache":
+			if on, err = switchOn(f); err == nil {
+				opts.settings = append(opts.settings, setting{"response_cache", strconv.FormatBool(!on), flag})
+			}
+		default:
+			opts.settings = append(opts.settings, setting{strings.ReplaceAll(f.Name, "-", "_"), f.Value, flag})
+		}
+		if err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// switchOn returns the value of a switch, given as --name or --name=true|false
+func switchOn(f cli.FlagValue) (bool, error) {
+	on, err := strconv.ParseBool(f.Value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for --%s: %s (use true or false)", f.Name, f.Value)
+	}
+	return on, nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/config"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+// configCommands are the subcommands of `zchat config`
+var configCommands = []string{"init", "show", "get", "set", "validate"}
+
+const (
+	// defaultAnthropicModel is offered by `zchat config init` for Anthropic
+	defaultAnthropicModel = "claude-sonnet-4-5-20250929"
+	// ollamaProbeTimeout bounds the check for a running Ollama
+	ollamaProbeTimeout = 2 * time.Second
+)
+
+// runConfig handles `zchat config init|show|get|set|validate`
+func runConfig(args []string, opts options) {
+	if len(args) == 0 {
+		configUsage()
+	}
+
+	switch {
+	case args[0] == "init" && len(args) == 1:
+		runConfigInit()
+	case args[0] == "show" && len(args) == 1:
+		cfg, sources, err := loadConfigWithSources(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		out, err := cfg.Show(sources)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("# Config files, each overriding the ones before:")
+		for _, path := range config.Files() {
+			if _, err := os.Stat(path); err != nil {
+				fmt.Printf("#   %s (not found)\n", path)
+			} else {
+				fmt.Printf("#   %s\n", path)
+			}
+		}
+		if cfg.Profile != "" {
+			fmt.Printf("# Profile: %s (%s)\n", cfg.Profile, sources.Get("profile"))
+		}
+		fmt.Print(out)
+	case args[0] == "get" && len(args) == 2:
+		cfg, err := loadConfig(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		value, err := cfg.Value(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+	case args[0] == "set" && len(args) >= 3:
+		path := configPath()
+		if err := config.Set(path, args[1], strings.Join(args[2:], " ")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Set %s in %s\n", args[1], path)
+	case args[0] == "validate" && len(args) <= 2:
+		paths := config.Files()
+		if len(args) == 2 {
+			paths = args[1:]
+		}
+		runConfigValidate(paths)
+	default:
+		configUsage()
+	}
+}
+
+// configUsage prints the usage of `zchat config` and exits
+func configUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: zchat config init              set up the config file interactively")
+	fmt.Fprintln(os.Stderr, "       zchat config show              show the effective config and where each value comes from")
+	fmt.Fprintln(os.Stderr, "       zchat config get <key>")
+	fmt.Fprintln(os.Stderr, "       zchat config set <key> <value> change a setting in the user config file")
+	fmt.Fprintln(os.Stderr, "       zchat config validate [file]   check the config files for mistakes")
+	os.Exit(1)
+}
+
+// configPath returns the user's config file path, exiting if there is no home directory
+func configPath() string {
+	path, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return path
+}
+
+// runConfigValidate reports every problem in the config files at paths, exiting with 1 if
+// any of them keeps a file from loading. Missing files are skipped, since every layer is optional.
+func runConfigValidate(paths []string) {
+	failed, found := false, false
+	for _, path := range paths {
+		problems, err := config.Check(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		found = true
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+			continue
+		}
+
+		fileFailed := false
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+			fileFailed = fileFailed || !p.Warning
+		}
+		if !fileFailed {
+			fmt.Printf("%s: OK\n", path)
+		}
+		failed = failed || fileFailed
+	}
+
+	if !found {
+		fmt.Printf("No config file at %s; the defaults are used.\n", strings.Join(paths, ", "))
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runConfigInit writes a config file from the answers to a few questions, checking for a
+// running Ollama and offering the models it has
+func runConfigInit() {
+	display := ui.NewDisplay()
+	if !display.HasTTY() {
+		fmt.Fprintln(os.Stderr, "Error: zchat config init needs a terminal to ask on; use zchat config set instead")
+		os.Exit(1)
+	}
+	ask := func(question, defaultValue string) string {
+		answer, err := display.Ask(question, defaultValue)
+		if err != nil {
+			fmt.Println()
+			os.Exit(1)
+		}
+		return answer
+	}
+
+	path := configPath()
+	if _, err := os.Stat(path); err == nil {
+		if answer := ask(fmt.Sprintf("%s already exists. Overwrite it?", path), "n"); !strings.HasPrefix(strings.ToLower(answer), "y") {
+			return
+		}
+	}
+
+	defaults := config.Default()
+	ollamaURL := defaults.OllamaURL
+	if url := os.Getenv("OLLAMA_URL"); url != "" {
+		ollamaURL = url
+	}
+	models, ollamaErr := probeOllama(ollamaURL)
+	if ollamaErr == nil {
+		fmt.Printf("Found Ollama at %s with %d model(s).\n", ollamaURL, len(models))
+	} else {
+		fmt.Printf("No Ollama running at %s.\n", ollamaURL)
+	}
+
+	provider := "ollama"
+	if ollamaErr != nil && os.Getenv("ANTHROPIC_API_KEY") != "" {
+		provider = "anthropic"
+	}
+	for {
+		provider = ask("Provider (ollama or anthropic)", provider)
+		if provider == "ollama" || provider == "anthropic" {
+			break
+		}
+		fmt.Println("Please answer ollama or anthropic.")
+	}
+
+	settings := []config.Setting{{Key: "provider", Value: provider}}
+	var model string
+	if provider == "ollama" {
+		if ollamaErr != nil {
+			ollamaURL = ask("Ollama URL", ollamaURL)
+			models, ollamaErr = probeOllama(ollamaURL)
+		}
+		if ollamaURL != defaults.OllamaURL {
+			settings = append(settings, config.Setting{Key: "ollama_url", Value: ollamaURL})
+		}
+		model = chooseModel(ask, models, defaults.Model)
+		if ollamaErr != nil || !slices.Contains(models, model) {
+			fmt.Printf("Remember to start Ollama and pull the model: ollama pull %s\n", model)
+		}
+	} else {
+		model = ask("Model", defaultAnthropicModel)
+		if os.Getenv("ANTHROPIC_API_KEY") != "" {
+			fmt.Println("Using ANTHROPIC_API_KEY from the environment.")
+		} else if key := ask("Anthropic API key (leave empty to set ANTHROPIC_API_KEY instead)", ""); key != "" {
+			settings = append(settings, config.Setting{Key: "api_key", Value: key})
+		}
+	}
+	settings = append(settings, config.Setting{Key: "model", Value: model})
+
+	header := "zchat configuration, written by `zchat config init`.\nRun `zchat config show` to see every setting and where its value comes from."
+	if err := config.WriteFile(path, header, settings); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %s\n", path)
+
+	// An Anthropic setup without a key is written anyway, but needs the variable before use
+	if problems, err := config.Check(path); err == nil {
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", p.Message)
+		}
+	}
+}
+
+// probeOllama lists the models of the Ollama server at url, failing if none is running
+func probeOllama(url string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaProbeTimeout)
+	defer cancel()
+
+	return llm.NewOllamaClient(url, "").ListModels(ctx)
+}
+
+// chooseModel offers the installed models by number, or asks for a name if there are none
+func chooseModel(ask func(question, defaultValue string) string, models []string, defaultModel string) string {
+	if len(models) == 0 {
+		return ask("Model", defaultModel)
+	}
+
+	choice := models[0]
+	for i, m := range models {
+		fmt.Printf("  %d. %s\n", i+1, m)
+		if m == defaultModel {
+			choice = m
+		}
+	}
+	answer := ask("Model (number or name)", choice)
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(models) {
+		return models[n-1]
+	}
+	return answer
+}
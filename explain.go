@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/config"
+	contextPkg "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/redact"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+// runExplain handles `zchat explain [--json] <command>`. The command is only analyzed, never executed.
+func runExplain(args []string, opts options) {
+	jsonOutput := opts.json
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: zchat explain [--json] '<command>'")
+		os.Exit(1)
+	}
+	command := strings.Join(args, " ")
+
+	// Load config
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	llmClient, err := newClient(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	// With --output json the explanation is part of the usual result object; --json prints it bare
+	if opts.output == "json" {
+		display := newJSONDisplay()
+		display.ShowRequest("", cfg.Provider, cfg.Model)
+		display.ShowCommand(command, assessCommand(cfg, command))
+		exp, err := explainCommand(cfg, display, llmClient, redactor, command, cfg.Verbose)
+		if err != nil {
+			fail(display, "explaining command", err)
+		}
+		showCacheHit(display, llmClient)
+		display.ShowExplanation(exp)
+		exit(display, 0)
+	}
+
+	display := ui.NewDisplay()
+	if jsonOutput {
+		display.DisableSpinner()
+	}
+	exp, err := explainCommand(cfg, display, llmClient, redactor, command, cfg.Verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error explaining command: %v\n", err)
+		os.Exit(1)
+	}
+	if !jsonOutput {
+		showCacheHit(display, llmClient)
+	}
+
+	if jsonOutput {
+		if err := display.ShowExplanationJSON(exp); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	display.ShowExplanation(exp)
+}
+
+// showExplanation explains command alongside it, for --explain; a failure only costs the explanation
+func showExplanation(cfg *config.Config, display ui.Display, client llm.Client, redactor *redact.Redactor, command string) {
+	exp, err := explainCommand(cfg, display, client, redactor, command, cfg.Verbose)
+	if err != nil {
+		display.ShowError(fmt.Errorf("explaining command: %w", err))
+		return
+	}
+	display.ShowExplanation(exp)
+}
+
+// explainCommand asks the model for a breakdown of command and overlays the dangerous-pattern verdict
+func explainCommand(cfg *config.Config, display ui.Display, client llm.Client, redactor *redact.Redactor, command string, verbose bool) (*llm.Explanation, error) {
+	// Pasted commands often carry tokens; mask them before sending
+	redacted, findings := redactor.Redact(command)
+	if verbose {
+		display.ShowRedactions(findings)
+	}
+
+	// Only the platform and shell are needed to explain a command
+	sysCtx := &contextPkg.SystemContext{
+		Shell: contextPkg.DetectShell(),
+		OS:    runtime.GOOS,
+		Arch:  runtime.GOARCH,
+	}
+
+	ctx, done := startRequest(display, cfg)
+	defer done()
+
+	exp, err := client.ExplainCommand(ctx, redacted, sysCtx)
+	if err != nil {
+		return nil, requestError(ctx, err)
+	}
+	exp.Command = command
+
+	// The configured dangerous patterns always win over the model's verdict
+	if isDangerous, reason := executor.IsDangerous(command, cfg.DangerousPatterns); isDangerous {
+		exp.Risk.Level = executor.RiskHigh
+		exp.Risk.Reasons = append([]string{reason}, exp.Risk.Reasons...)
+	}
+
+	return exp, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/config"
+	contextPkg "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/history"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+// defaultHistoryLimit is how many entries `zchat history` shows
+const defaultHistoryLimit = 20
+
+// recorder appends interactions to the command history when it is enabled
+type recorder struct {
+	store *history.Store
+	cfg   *config.Config
+}
+
+// newRecorder creates a recorder, or one that records nothing if history is disabled
+func newRecorder(cfg *config.Config, stateDir string) *recorder {
+	r := &recorder{cfg: cfg}
+	if cfg.RecordHistory && stateDir != "" {
+		r.store = history.NewStore(stateDir)
+	}
+	return r
+}
+
+// newEntry starts a history entry for a generated command
+func (r *recorder) newEntry(query, command string, sysCtx *contextPkg.SystemContext) *history.Entry {
+	return &history.Entry{
+		Query:    query,
+		Context:  sysCtx.Summary(),
+		Provider: r.cfg.Provider,
+		Model:    r.cfg.Model,
+		Command:  command,
+	}
+}
+
+// save appends an entry; history is a convenience, so failures are reported but not fatal
+func (r *recorder) save(entry *history.Entry) {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.Add(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record history: %v\n", err)
+	}
+}
+
+// executeRecorded runs command, bounded by the timeout setting, and fills in the entry's outcome
+func executeRecorded(cfg *config.Config, exec *executor.SafeExecutor, command string, entry *history.Entry) (*executor.Output, error) {
+	ctx, cancel := withTimeout(cfg)
+	defer cancel()
+
+	start := time.Now()
+	output, err := exec.Run(ctx, command)
+
+	entry.Executed = true
+	entry.ExitCode = executor.ExitCode(err)
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	return output, err
+}
+
+// runHistory handles `zchat history`, `zchat history search <text>` and `zchat history rerun <id>`
+func runHistory(args []string, opts options) {
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	stateDir, err := config.StateDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	store := history.NewStore(stateDir)
+	display := ui.NewDisplay()
+	setColor(display, cfg)
+
+	switch {
+	case len(args) == 0:
+		entries, err := store.List(defaultHistoryLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+			os.Exit(1)
+		}
+		display.ShowHistory(entries)
+	case args[0] == "search" && len(args) > 1:
+		entries, err := store.Search(strings.Join(args[1:], " "), defaultHistoryLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+			os.Exit(1)
+		}
+		display.ShowHistory(entries)
+	case args[0] == "rerun" && len(args) == 2:
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid history id: %s\n", args[1])
+			os.Exit(1)
+		}
+		rerun(cfg, display, store, id, stateDir)
+	default:
+		fmt.Fprintln(os.Stderr, "Usage: zchat history [search <text> | rerun <id>]")
+		os.Exit(1)
+	}
+}
+
+// rerun runs a recorded command again in the current directory. It is re-checked against the
+// current dangerous patterns, since the configuration may have changed since it was recorded.
+func rerun(cfg *config.Config, display ui.Display, store *history.Store, id int, stateDir string) {
+	previous, err := store.Get(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	sysCtx := &contextPkg.SystemContext{
+		Shell: contextPkg.DetectShell(),
+		OS:    runtime.GOOS,
+		Arch:  runtime.GOARCH,
+	}
+	sysCtx.WorkingDir, _ = os.Getwd()
+
+	if previous.Query != "" {
+		fmt.Printf("Query: %s\n", previous.Query)
+	}
+	display.ShowCommand(previous.Command, assessCommand(cfg, previous.Command))
+
+	rec := newRecorder(cfg, stateDir)
+	entry := rec.newEntry(previous.Query, previous.Command, sysCtx)
+	entry.Provider, entry.Model = previous.Provider, previous.Model
+	entry.RerunOf = previous.ID
+
+	if !confirmCommand(display, cfg, previous.Command) {
+		rec.save(entry)
+		exitNotRun(display, cfg)
+	}
+
+	output, err := executeRecorded(cfg, newExecutor(cfg, sysCtx), previous.Command, entry)
+	rec.save(entry)
+	display.ShowResult(output, err, time.Duration(entry.DurationMs)*time.Millisecond)
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/shellinit"
+)
+
+// runInit handles `zchat init <shell>`, printing the integration script to eval in the shell's rc file
+func runInit(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: zchat init <%s>\n", strings.Join(shellinit.Shells, "|"))
+		os.Exit(1)
+	}
+
+	// Call this binary by its full path, so the widget works even if PATH differs
+	binary, err := os.Executable()
+	if err != nil {
+		binary = "zchat"
+	}
+
+	script, err := shellinit.Script(args[0], binary)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(script)
+}
+
+// runCompletion handles `zchat completion <shell>`, printing the completion script generated
+// from the command-line definitions
+func runCompletion(shell string) {
+	script, err := commandLine.Completion(shell)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(script)
+}
@@ -0,0 +1,214 @@
+// Package cli parses the command line from definitions of the commands and flags, which
+// also produce the help text and the shell completion scripts
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Flag defines a command-line flag
+type Flag struct {
+	Name    string   // long name, given as --name
+	Short   string   // one-letter alias, given as -s, or ""
+	Arg     string   // what the value is, for the help; "" for a switch, which takes no value
+	Default string   // value of a flag with an optional value given without one, as --name
+	Values  []string // values offered by completion
+	Usage   string
+	Hidden  bool // left out of the help, but still accepted and completed
+}
+
+// switchValue is the value of a switch given without one
+const switchValue = "true"
+
+// optional reports whether the flag's value may be left out, in which case it can only be
+// given after =, as --name=value
+func (f *Flag) optional() bool {
+	return f.Arg != "" && f.Default != ""
+}
+
+// takesValue reports whether the flag's value may be the next argument
+func (f *Flag) takesValue() bool {
+	return f.Arg != "" && f.Default == ""
+}
+
+// Command defines a subcommand
+type Command struct {
+	Name        string
+	Args        string // its arguments, for the help
+	Usage       string
+	Flags       []Flag   // flags of this command only, besides the global ones
+	Subcommands []string // words completed after the command name
+}
+
+// Spec defines a program's command line
+type Spec struct {
+	Name     string
+	Args     string // the arguments when no command is given, for the help
+	Flags    []Flag // global flags, accepted before and after the command name
+	Commands []Command
+}
+
+// Command returns the command called name, or nil
+func (s *Spec) Command(name string) *Command {
+	for i := range s.Commands {
+		if s.Commands[i].Name == name {
+			return &s.Commands[i]
+		}
+	}
+	return nil
+}
+
+// FlagValue is a flag given on the command line
+type FlagValue struct {
+	Name  string // the flag's long name
+	Value string
+}
+
+// Invocation is a parsed command line
+type Invocation struct {
+	Flags   []FlagValue // in the order given, so later flags can override earlier ones
+	Command string      // the command named by the first argument, if any
+	Args    []string    // the arguments after the flags
+	Literal bool        // the arguments followed --, so they don't name a command
+}
+
+// Flag returns the value of the last occurrence of the flag called name, and whether it was given
+func (inv *Invocation) Flag(name string) (string, bool) {
+	for i := len(inv.Flags) - 1; i >= 0; i-- {
+		if inv.Flags[i].Name == name {
+			return inv.Flags[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Parse parses args. Flags come first; the first argument that isn't a flag may name a
+// command, which can be followed by more flags. Parsing stops at the first other argument,
+// so the rest is kept as is even if it looks like flags, or after --, which also keeps the
+// first argument from naming a command.
+func (s *Spec) Parse(args []string) (*Invocation, error) {
+	inv := &Invocation{}
+	flags := s.Flags
+
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			inv.Literal = inv.Command == ""
+			inv.Args = args[1:]
+			return inv, nil
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			value, n, err := parseFlag(flags, args)
+			if err != nil {
+				return nil, err
+			}
+			inv.Flags = append(inv.Flags, value)
+			args = args[n:]
+		case inv.Command == "" && s.Command(arg) != nil:
+			inv.Command = arg
+			flags = append(slices.Clip(s.Flags), s.Command(arg).Flags...)
+			args = args[1:]
+		default:
+			inv.Args = args
+			return inv, nil
+		}
+	}
+	return inv, nil
+}
+
+// parseFlag parses the flag in args[0], returning it and how many arguments it took
+func parseFlag(flags []Flag, args []string) (FlagValue, int, error) {
+	arg := args[0]
+	name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+
+	var flag *Flag
+	for i := range flags {
+		f := &flags[i]
+		if strings.HasPrefix(arg, "--") && f.Name == name || !strings.HasPrefix(arg, "--") && f.Short != "" && f.Short == name {
+			flag = f
+			break
+		}
+	}
+	if flag == nil {
+		return FlagValue{}, 0, fmt.Errorf("unknown flag %s", strings.SplitN(arg, "=", 2)[0])
+	}
+
+	switch {
+	case hasValue:
+		return FlagValue{Name: flag.Name, Value: value}, 1, nil
+	case flag.Arg == "":
+		return FlagValue{Name: flag.Name, Value: switchValue}, 1, nil
+	case flag.optional():
+		return FlagValue{Name: flag.Name, Value: flag.Default}, 1, nil
+	case len(args) < 2:
+		return FlagValue{}, 0, fmt.Errorf("flag %s needs a value (%s)", arg, flag.Arg)
+	default:
+		return FlagValue{Name: flag.Name, Value: args[1]}, 2, nil
+	}
+}
+
+// Usage returns the help: how to call the program, then its commands and flags
+func (s *Spec) Usage() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s [flags] %s\n", s.Name, s.Args)
+	fmt.Fprintf(&b, "       %s [flags] <command> [arguments]\n", s.Name)
+
+	b.WriteString("\nCommands:\n")
+	var rows [][2]string
+	for _, c := range s.Commands {
+		rows = append(rows, [2]string{strings.TrimSpace(c.Name + " " + c.Args), c.Usage})
+	}
+	writeRows(&b, rows)
+
+	b.WriteString("\nFlags:\n")
+	writeRows(&b, flagRows(s.Flags))
+	return b.String()
+}
+
+// CommandUsage returns the help of one command
+func (s *Spec) CommandUsage(c *Command) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s %s [flags] %s\n", s.Name, c.Name, c.Args)
+	fmt.Fprintf(&b, "\n%s\n", c.Usage)
+	if len(c.Flags) > 0 {
+		b.WriteString("\nFlags:\n")
+		writeRows(&b, flagRows(c.Flags))
+	}
+	fmt.Fprintf(&b, "\nThe global flags are accepted too, see %s --help.\n", s.Name)
+	return b.String()
+}
+
+// flagRows lists the flags that aren't hidden for the help
+func flagRows(flags []Flag) [][2]string {
+	var rows [][2]string
+	for _, f := range flags {
+		if f.Hidden {
+			continue
+		}
+		name := "    --" + f.Name
+		if f.Short != "" {
+			name = "-" + f.Short + ", --" + f.Name
+		}
+		switch {
+		case f.optional():
+			name += "[=" + f.Arg + "]"
+		case f.Arg != "":
+			name += " <" + f.Arg + ">"
+		}
+		rows = append(rows, [2]string{name, f.Usage})
+	}
+	return rows
+}
+
+// writeRows writes two columns, the second aligned
+func writeRows(b *strings.Builder, rows [][2]string) {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r[0]))
+	}
+	for _, r := range rows {
+		fmt.Fprintf(b, "  %-*s  %s\n", width, r[0], r[1])
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testSpec is a small command line like zchat's
+var testSpec = Spec{
+	Name: "zchat",
+	Args: "<query>",
+	Flags: []Flag{
+		{Name: "model", Arg: "name", Usage: "ask this model"},
+		{Name: "output", Arg: "format", Values: []string{"text", "json"}, Usage: "output format"},
+		{Name: "color", Arg: "when", Default: "always", Values: []string{"auto", "always", "never"}, Usage: "highlight commands"},
+		{Name: "yes", Short: "y", Usage: "run without asking"},
+		{Name: "max-fix-rounds", Arg: "value", Usage: "set max_fix_rounds", Hidden: true},
+	},
+	Commands: []Command{
+		{Name: "ask", Args: "<query>", Usage: "ask for a command"},
+		{Name: "explain", Args: "<command>", Usage: "explain a command", Flags: []Flag{{Name: "json", Usage: "print JSON"}}},
+		{Name: "config", Args: "show|get", Usage: "inspect the configuration", Subcommands: []string{"show", "get"}},
+	},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		flags   []FlagValue
+		command string
+		rest    []string
+		literal bool
+	}{
+		{"query", []string{"list", "files"}, nil, "", []string{"list", "files"}, false},
+		{"flags", []string{"--model", "llama3", "--output=json", "-y", "list"}, []FlagValue{{"model", "llama3"}, {"output", "json"}, {"yes", "true"}}, "", []string{"list"}, false},
+		{"switch value", []string{"--yes=false", "list"}, []FlagValue{{"yes", "false"}}, "", []string{"list"}, false},
+		{"optional value", []string{"--color", "list"}, []FlagValue{{"color", "always"}}, "", []string{"list"}, false},
+		{"optional value given", []string{"--color=never", "list"}, []FlagValue{{"color", "never"}}, "", []string{"list"}, false},
+		{"hidden flag", []string{"--max-fix-rounds", "1", "fix", "it"}, []FlagValue{{"max-fix-rounds", "1"}}, "", []string{"fix", "it"}, false},
+		{"command", []string{"-y", "explain", "--json", "--model", "x", "ls", "-la"}, []FlagValue{{"yes", "true"}, {"json", "true"}, {"model", "x"}}, "explain", []string{"ls", "-la"}, false},
+		{"query keeps flags", []string{"find", "-name", "*.go"}, nil, "", []string{"find", "-name", "*.go"}, false},
+		{"literal", []string{"-y", "--", "config", "--help"}, []FlagValue{{"yes", "true"}}, "", []string{"config", "--help"}, true},
+		{"literal after command", []string{"ask", "--", "--help", "me"}, nil, "ask", []string{"--help", "me"}, false},
+		{"empty", nil, nil, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := testSpec.Parse(tt.args)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if !slices.Equal(inv.Flags, tt.flags) {
+				t.Errorf("Expected flags %v, got %v", tt.flags, inv.Flags)
+			}
+			if inv.Command != tt.command {
+				t.Errorf("Expected command %q, got %q", tt.command, inv.Command)
+			}
+			if !slices.Equal(inv.Args, tt.rest) {
+				t.Errorf("Expected args %q, got %q", tt.rest, inv.Args)
+			}
+			if inv.Literal != tt.literal {
+				t.Errorf("Expected literal %v, got %v", tt.literal, inv.Literal)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string][]string{
+		"unknown flag --bogus":         {"--bogus=1", "list"},
+		"unknown flag -x":              {"-x", "list"},
+		"flag --model needs a value":   {"--model"},
+		"unknown flag --json":          {"--json", "explain", "ls"}, // only explain takes --json
+		"unknown flag --max-fix-round": {"--max-fix-round", "2"},
+	}
+
+	for expected, args := range tests {
+		_, err := testSpec.Parse(args)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Parse(%q): expected error %q, got %v", args, expected, err)
+		}
+	}
+}
+
+func TestInvocation_Flag(t *testing.T) {
+	inv, _ := testSpec.Parse([]string{"--model", "a", "--model=b", "list"})
+
+	if value, ok := inv.Flag("model"); !ok || value != "b" {
+		t.Errorf("Expected the last --model, got %q", value)
+	}
+	if _, ok := inv.Flag("output"); ok {
+		t.Error("Expected --output not to be given")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	usage := testSpec.Usage()
+
+	for _, want := range []string{
+		"Usage: zchat [flags] <query>",
+		"  explain <command>  explain a command",
+		"      --model <name>     ask this model",
+		"  -y, --yes              run without asking",
+		"      --color[=when]     highlight commands",
+	} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("Expected usage to contain %q, got:\n%s", want, usage)
+		}
+	}
+	if strings.Contains(usage, "max-fix-rounds") {
+		t.Errorf("Expected hidden flags to be left out, got:\n%s", usage)
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	usage := testSpec.CommandUsage(testSpec.Command("explain"))
+
+	for _, want := range []string{"Usage: zchat explain [flags] <command>", "explain a command", "--json  print JSON"} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("Expected usage to contain %q, got:\n%s", want, usage)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shells are the shells completion scripts can be generated for
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Completion returns the completion script for shell
+func (s *Spec) Completion(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return s.bashCompletion(), nil
+	case "zsh":
+		return s.zshCompletion(), nil
+	case "fish":
+		return s.fishCompletion(), nil
+	default:
+		return "", fmt.Errorf("unsupported shell: %s (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+}
+
+// bashCompletion completes flags, their values, the command and its subcommand. Bash splits
+// --flag=value at the =, so both forms look the same. Anything else, like the words of a
+// query, falls back to file names.
+func (s *Spec) bashCompletion() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s completion for bash. Add to ~/.bashrc:  eval \"$(%s completion bash)\"\n", s.Name, s.Name)
+	fmt.Fprintf(&b, "_%s_complete() {\n", s.Name)
+	b.WriteString("  local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} eq=\n")
+	b.WriteString("  if [[ $cur == = ]]; then\n")
+	b.WriteString("    cur= eq=1\n")
+	b.WriteString("  elif [[ $prev == = ]]; then\n")
+	b.WriteString("    prev=${COMP_WORDS[COMP_CWORD-2]} eq=1\n")
+	b.WriteString("  fi\n")
+	b.WriteString("\n  # The arguments before the cursor, without flags and their values\n")
+	b.WriteString("  local words=() i\n")
+	b.WriteString("  for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("    case ${COMP_WORDS[i]} in\n")
+	b.WriteString("      --) return 0 ;;\n")
+	if pattern := bashPattern(s.valueFlags()); pattern != "" {
+		fmt.Fprintf(&b, "      %s) [[ ${COMP_WORDS[i+1]} == = ]] && ((i++)); ((i++)) ;;\n", pattern)
+	}
+	b.WriteString("      =) ((i++)) ;;\n")
+	b.WriteString("      -*) ;;\n")
+	b.WriteString("      *) words+=(\"${COMP_WORDS[i]}\") ;;\n")
+	b.WriteString("    esac\n")
+	b.WriteString("  done\n")
+
+	b.WriteString("\n  case $prev in\n")
+	for _, f := range s.allFlags() {
+		if len(f.Values) == 0 {
+			continue
+		}
+		complete := fmt.Sprintf("COMPREPLY=($(compgen -W %s -- \"$cur\")); return 0", shellQuote(strings.Join(f.Values, " ")))
+		switch {
+		case f.takesValue():
+			fmt.Fprintf(&b, "    %s) %s ;;\n", bashPattern([]Flag{f}), complete)
+		case f.optional():
+			// Only --flag=value; a bare --flag takes no value
+			fmt.Fprintf(&b, "    %s) if [[ $eq ]]; then %s; fi ;;\n", bashPattern([]Flag{f}), complete)
+		}
+	}
+	b.WriteString("  esac\n")
+
+	b.WriteString("\n  if [[ $cur == -* ]]; then\n")
+	fmt.Fprintf(&b, "    local flags=%s\n", shellQuote(strings.Join(flagNames(s.Flags), " ")))
+	b.WriteString("    case ${words[0]} in\n")
+	for _, c := range s.Commands {
+		if len(c.Flags) > 0 {
+			fmt.Fprintf(&b, "      %s) flags+=%s ;;\n", c.Name, shellQuote(" "+strings.Join(flagNames(c.Flags), " ")))
+		}
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	b.WriteString("    return 0\n")
+	b.WriteString("  fi\n")
+
+	b.WriteString("\n  case ${#words[@]} in\n")
+	fmt.Fprintf(&b, "    0) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n", shellQuote(strings.Join(s.commandNames(), " ")))
+	b.WriteString("    1)\n")
+	b.WriteString("      case ${words[0]} in\n")
+	for _, c := range s.Commands {
+		if len(c.Subcommands) > 0 {
+			fmt.Fprintf(&b, "        %s) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n", c.Name, shellQuote(strings.Join(c.Subcommands, " ")))
+		}
+	}
+	b.WriteString("      esac\n")
+	b.WriteString("      ;;\n")
+	b.WriteString("  esac\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -o default -F _%s_complete %s\n", s.Name, s.Name)
+	return b.String()
+}
+
+// zshCompletion describes the flags and commands to _arguments
+func (s *Spec) zshCompletion() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", s.Name)
+	fmt.Fprintf(&b, "# %s completion for zsh. Add to ~/.zshrc, after compinit:  eval \"$(%s completion zsh)\"\n", s.Name, s.Name)
+	fmt.Fprintf(&b, "_%s() {\n", s.Name)
+	b.WriteString("  local curcontext=$curcontext state line\n")
+	b.WriteString("  local -a commands=(\n")
+	for _, c := range s.Commands {
+		fmt.Fprintf(&b, "    %s\n", shellQuote(c.Name+":"+c.Usage))
+	}
+	b.WriteString("  )\n")
+
+	b.WriteString("  _arguments -S \\\n")
+	for _, f := range s.Flags {
+		fmt.Fprintf(&b, "    %s \\\n", zshFlagSpec(f))
+	}
+	b.WriteString("    '1: :->command' \\\n")
+	b.WriteString("    '*:: :->args' && return 0\n")
+
+	b.WriteString("\n  case $state in\n")
+	b.WriteString("    command) _describe -t commands command commands; _files ;;\n")
+	b.WriteString("    args)\n")
+	b.WriteString("      case $line[1] in\n")
+	for _, c := range s.Commands {
+		if len(c.Flags) == 0 && len(c.Subcommands) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s) _arguments", c.Name)
+		for _, f := range c.Flags {
+			fmt.Fprintf(&b, " %s", zshFlagSpec(f))
+		}
+		if len(c.Subcommands) > 0 {
+			fmt.Fprintf(&b, " %s", shellQuote("1: :("+strings.Join(c.Subcommands, " ")+")"))
+		}
+		b.WriteString(" '*: :_files' ;;\n")
+	}
+	b.WriteString("        *) _files ;;\n")
+	b.WriteString("      esac\n")
+	b.WriteString("      ;;\n")
+	b.WriteString("  esac\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "compdef _%s %s\n", s.Name, s.Name)
+	return b.String()
+}
+
+// zshFlagSpec returns the _arguments spec of a flag, with its short alias if it has one
+func zshFlagSpec(f Flag) string {
+	usage := "[" + zshEscape(f.Usage) + "]"
+	var arg string
+	switch {
+	case f.optional():
+		arg = "::" + f.Arg + ":" + zshValues(f.Values)
+	case f.Arg != "":
+		arg = ":" + f.Arg + ":" + zshValues(f.Values)
+	}
+
+	long := "--" + f.Name
+	switch {
+	case f.optional():
+		long += "=-"
+	case f.Arg != "":
+		long += "="
+	}
+	if f.Short == "" {
+		return shellQuote(long + usage + arg)
+	}
+	exclusive := "(-" + f.Short + " --" + f.Name + ")"
+	return shellQuote(exclusive) + "{-" + f.Short + "," + shellQuote(long) + "}" + shellQuote(usage+arg)
+}
+
+// zshValues returns the _arguments action completing values, or nothing for a free value
+func zshValues(values []string) string {
+	if len(values) == 0 {
+		return " "
+	}
+	return "(" + strings.Join(values, " ") + ")"
+}
+
+// zshEscape escapes the characters that would end a description in brackets
+func zshEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// fishCompletion declares each flag and command with fish's complete builtin
+func (s *Spec) fishCompletion() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s completion for fish. Add to ~/.config/fish/config.fish:  %s completion fish | source\n", s.Name, s.Name)
+	fmt.Fprintf(&b, "complete -c %s -e\n", s.Name)
+	for _, f := range s.Flags {
+		b.WriteString(fishFlag(s.Name, "", f))
+	}
+
+	for _, c := range s.Commands {
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", s.Name, c.Name, fishQuote(c.Usage))
+		for _, f := range c.Flags {
+			b.WriteString(fishFlag(s.Name, "__fish_seen_subcommand_from "+c.Name, f))
+		}
+		if len(c.Subcommands) > 0 {
+			condition := "__fish_seen_subcommand_from " + c.Name + "; and not __fish_seen_subcommand_from " + strings.Join(c.Subcommands, " ")
+			fmt.Fprintf(&b, "complete -c %s -n %s -f -a %s\n", s.Name, fishQuote(condition), fishQuote(strings.Join(c.Subcommands, " ")))
+		}
+	}
+	return b.String()
+}
+
+// fishFlag declares a flag, for every command or under a condition
+func fishFlag(program, condition string, f Flag) string {
+	line := "complete -c " + program
+	if condition != "" {
+		line += " -n " + fishQuote(condition)
+	}
+	if f.Short != "" {
+		line += " -s " + f.Short
+	}
+	line += " -l " + f.Name
+	if f.takesValue() {
+		line += " -r"
+		if len(f.Values) > 0 {
+			line += " -f -a " + fishQuote(strings.Join(f.Values, " "))
+		}
+	}
+	return line + " -d " + fishQuote(f.Usage) + "\n"
+}
+
+// allFlags returns the global flags and those of every command
+func (s *Spec) allFlags() []Flag {
+	flags := append([]Flag{}, s.Flags...)
+	for _, c := range s.Commands {
+		flags = append(flags, c.Flags...)
+	}
+	return flags
+}
+
+// valueFlags returns the flags whose value may be the next argument
+func (s *Spec) valueFlags() []Flag {
+	var flags []Flag
+	for _, f := range s.allFlags() {
+		if f.takesValue() {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+// commandNames returns the names of the commands
+func (s *Spec) commandNames() []string {
+	names := make([]string, len(s.Commands))
+	for i, c := range s.Commands {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// flagNames returns every spelling of the flags, for completing a word starting with -
+func flagNames(flags []Flag) []string {
+	var names []string
+	for _, f := range flags {
+		if f.Short != "" {
+			names = append(names, "-"+f.Short)
+		}
+		names = append(names, "--"+f.Name)
+	}
+	return names
+}
+
+// bashPattern returns a case pattern matching the flags, like -m|--model
+func bashPattern(flags []Flag) string {
+	var names []string
+	for _, f := range flags {
+		names = append(names, flagNames([]Flag{f})...)
+	}
+	return strings.Join(names, "|")
+}
+
+// shellQuote single-quotes s for bash and zsh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote single-quotes s for fish, where backslash escapes quotes and itself inside quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
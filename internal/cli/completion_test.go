@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletion_Bash(t *testing.T) {
+	script, err := testSpec.Completion("bash")
+	if err != nil {
+		t.Fatalf("Completion() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"complete -o default -F _zchat_complete zchat",
+		"--model|--output|--max-fix-rounds) [[ ${COMP_WORDS[i+1]} == = ]] && ((i++)); ((i++)) ;;",
+		`--output) COMPREPLY=($(compgen -W 'text json' -- "$cur")); return 0 ;;`,
+		`--color) if [[ $eq ]]; then COMPREPLY=($(compgen -W 'auto always never' -- "$cur")); return 0; fi ;;`,
+		`explain) flags+=' --json' ;;`,
+		`config) COMPREPLY=($(compgen -W 'show get' -- "$cur")) ;;`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestCompletion_BashRun(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	script, _ := testSpec.Completion("bash")
+	path := filepath.Join(t.TempDir(), "zchat.bash")
+	os.WriteFile(path, []byte(script), 0644)
+
+	// Each case is the words of the command line, the last one being completed
+	tests := map[string]string{
+		`zchat ""`:                       "ask explain config",
+		`zchat co`:                       "config",
+		`zchat --model llama config ""`:  "show get",
+		`zchat --model = llama config s`: "show",
+		`zchat --output ""`:              "text json",
+		`zchat --output = j`:             "json",
+		`zchat --color = ""`:             "auto always never",
+		`zchat --color ""`:               "ask explain config",
+		`zchat explain --j`:              "--json",
+		`zchat -`:                        "--model --output --color -y --yes --max-fix-rounds",
+		`zchat list files ""`:            "",
+		`zchat -- co`:                    "",
+	}
+
+	for words, expected := range tests {
+		out, err := exec.Command(bash, "-c", `source "$1"; COMP_WORDS=(`+words+`); COMP_CWORD=$((${#COMP_WORDS[@]} - 1)); _zchat_complete; echo "${COMPREPLY[*]}"`, "bash", path).CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash failed for %s: %v\n%s", words, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != expected {
+			t.Errorf("Completing %s: expected %q, got %q", words, expected, got)
+		}
+	}
+}
+
+func TestCompletion_Zsh(t *testing.T) {
+	script, err := testSpec.Completion("zsh")
+	if err != nil {
+		t.Fatalf("Completion() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"#compdef zchat",
+		"'ask:ask for a command'",
+		"'--model=[ask this model]:name: '",
+		"'--output=[output format]:format:(text json)'",
+		"'--color=-[highlight commands]::when:(auto always never)'",
+		"'(-y --yes)'{-y,'--yes'}'[run without asking]'",
+		"explain) _arguments '--json[print JSON]' '*: :_files' ;;",
+		"config) _arguments '1: :(show get)' '*: :_files' ;;",
+		"compdef _zchat zchat",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestCompletion_Fish(t *testing.T) {
+	script, err := testSpec.Completion("fish")
+	if err != nil {
+		t.Fatalf("Completion() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"complete -c zchat -l model -r -d 'ask this model'",
+		"complete -c zchat -l output -r -f -a 'text json' -d 'output format'",
+		"complete -c zchat -s y -l yes -d 'run without asking'",
+		"complete -c zchat -n __fish_use_subcommand -a explain -d 'explain a command'",
+		"complete -c zchat -n '__fish_seen_subcommand_from explain' -l json -d 'print JSON'",
+		"complete -c zchat -n '__fish_seen_subcommand_from config; and not __fish_seen_subcommand_from show get' -f -a 'show get'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestCompletion_Unsupported(t *testing.T) {
+	if _, err := testSpec.Completion("tcsh"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+}
@@ -4,52 +4,126 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Provider          string   `yaml:"provider"` // "anthropic" or "ollama"
-	APIKey            string   `yaml:"api_key"`
-	Model             string   `yaml:"model"`
-	OllamaURL         string   `yaml:"ollama_url"`
-	MaxContextLines   int      `yaml:"max_context_lines"`
-	DangerousPatterns []string `yaml:"dangerous_patterns"`
+	Provider          string                   `yaml:"provider"` // "anthropic" or "ollama"
+	APIKey            string                   `yaml:"api_key"`
+	Model             string                   `yaml:"model"`
+	OllamaURL         string                   `yaml:"ollama_url"`
+	MaxContextLines   int                      `yaml:"max_context_lines"`
+	DangerousPatterns []string                 `yaml:"dangerous_patterns"`
+	ShellHistory      bool                     `yaml:"shell_history"` // opt-in: include recent shell history in the prompt
+	HistoryEntries    int                      `yaml:"history_entries"`
+	HistoryMaxChars   int                      `yaml:"history_max_chars"`
+	ShellAliases      bool                     `yaml:"shell_aliases"`     // opt-in: tell the model about aliases and functions
+	ExecWithAliases   bool                     `yaml:"exec_with_aliases"` // run commands with rc files sourced so aliases expand
+	ContextProviders  []string                 `yaml:"context_providers"` // enabled providers in render order; empty means defaults
+	ContextBudget     int                      `yaml:"context_budget"`    // total characters shared by provider sections
+	ProviderLimits    map[string]ProviderLimit `yaml:"provider_limits"`
+	FilePreviewBytes  int                      `yaml:"file_preview_bytes"` // 0 disables previews of files named in the query
+	RedactPatterns    []string                 `yaml:"redact_patterns"`    // extra secret regexes masked before sending to the LLM
+	MaxFixRounds      int                      `yaml:"max_fix_rounds"`     // corrections offered after a command fails; 0 disables
+	RecordHistory     bool                     `yaml:"record_history"`     // keep a local log of queries and commands for `zchat history`
+	ResponseCache     bool                     `yaml:"response_cache"`     // reuse answers for repeated requests in an unchanged context
+	CacheTTL          time.Duration            `yaml:"cache_ttl"`
+	CacheMaxEntries   int                      `yaml:"cache_max_entries"`
+	Timeout           time.Duration            `yaml:"timeout"` // bounds each model request and each command execution; 0 disables
+	Approve           string                   `yaml:"approve"` // when commands run without asking: prompt, readonly, all or never
+	Color             string                   `yaml:"color"`   // highlight commands: auto, always or never
+	Verbose           bool                     `yaml:"verbose"`
+	Profile           string                   `yaml:"profile"`       // the profile to use when no flag, variable or rule picks one
+	Profiles          map[string]yaml.Node     `yaml:"profiles"`      // named overlays of these settings
+	ProfileRules      []ProfileRule            `yaml:"profile_rules"` // profiles picked by working directory
 }
 
+// Approval policies for generated commands
+const (
+	ApprovePrompt   = "prompt"   // ask before running anything
+	ApproveReadOnly = "readonly" // run read-only commands without asking
+	ApproveAll      = "all"      // run everything without asking, except dangerous commands
+	ApproveNever    = "never"    // only show commands, never run them
+)
+
+// Color settings for highlighted commands
+const (
+	ColorAuto   = "auto"   // color when writing to a terminal, unless NO_COLOR is set
+	ColorAlways = "always" // color even when writing to a pipe or file
+	ColorNever  = "never"  // never color
+)
+
+// ProviderLimit bounds a single context provider
+type ProviderLimit struct {
+	Timeout  time.Duration `yaml:"timeout"`
+	MaxChars int           `yaml:"max_chars"`
+}
+
+// defaultProviders are enabled when context_providers is not set. Only the directory listing
+// was sent before providers existed, so the others are opt-in rather than sent unasked.
+var defaultProviders = []string{"files"}
+
 // Load loads configuration from file and environment variables
 func Load() (*Config, error) {
+	cfg, _, err := LoadWithSources("")
+	return cfg, err
+}
+
+// LoadWithSources loads the configuration like Load, and also reports where each setting
+// got its value. Each layer overrides the ones before it: the built-in defaults, the system
+// config, the user config, the nearest project config, the active profile, then environment
+// variables. The profile is the one named, if any, or else picked by selectProfile.
+func LoadWithSources(profile string) (*Config, Sources, error) {
 	cfg := getDefaultConfig()
+	sources := Sources{}
 
-	// Try to load config file
-	configPath, err := getConfigPath()
-	if err == nil {
-		if data, err := os.ReadFile(configPath); err == nil {
-			// Config file exists, parse it
-			if err := yaml.Unmarshal(data, cfg); err != nil {
-				return nil, fmt.Errorf("failed to parse config file: %w", err)
-			}
+	for _, path := range Files() {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue // every layer is optional
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cfg.apply(path, data, sources); err != nil {
+			return nil, nil, fmt.Errorf("invalid config file: %w", err)
 		}
-		// If file doesn't exist, that's OK - we'll use defaults
 	}
 
-	// Environment variables take precedence
-	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
-		cfg.APIKey = apiKey
-	}
-	if provider := os.Getenv("ZCHAT_PROVIDER"); provider != "" {
-		cfg.Provider = provider
-	}
-	if ollamaURL := os.Getenv("OLLAMA_URL"); ollamaURL != "" {
-		cfg.OllamaURL = ollamaURL
+	cwd, _ := os.Getwd()
+	if name, source := cfg.selectProfile(profile, cwd, sources); name != "" {
+		if err := cfg.useProfile(name, source, sources); err != nil {
+			return nil, nil, err
+		}
 	}
 
+	cfg.applyEnv(sources)
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return cfg, nil
+	return cfg, sources, nil
+}
+
+// applyEnv applies the environment variables, which take precedence over the config file
+func (c *Config) applyEnv(sources Sources) {
+	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
+		c.APIKey = apiKey
+		sources["api_key"] = "env ANTHROPIC_API_KEY"
+	}
+	if provider := os.Getenv("ZCHAT_PROVIDER"); provider != "" {
+		c.Provider = provider
+		sources["provider"] = "env ZCHAT_PROVIDER"
+	}
+	if ollamaURL := os.Getenv("OLLAMA_URL"); ollamaURL != "" {
+		c.OllamaURL = ollamaURL
+		sources["ollama_url"] = "env OLLAMA_URL"
+	}
 }
 
 // Validate checks if the configuration is valid
@@ -66,16 +140,78 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Timeout < 0 {
+		return fmt.Errorf("invalid timeout: %s (must not be negative)", c.Timeout)
+	}
+
+	switch c.Approve {
+	case "", ApprovePrompt, ApproveReadOnly, ApproveAll, ApproveNever:
+	default:
+		return fmt.Errorf("invalid approve: %s (must be 'prompt', 'readonly', 'all' or 'never')", c.Approve)
+	}
+
+	switch c.Color {
+	case "", ColorAuto, ColorAlways, ColorNever:
+	default:
+		return fmt.Errorf("invalid color: %s (must be 'auto', 'always' or 'never')", c.Color)
+	}
+
+	// Validate redaction patterns
+	for _, pattern := range c.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redact_patterns entry %q: %w", pattern, err)
+		}
+	}
+
 	return nil
 }
 
+// EnabledProviders returns the context providers to run, in order. Unless the list is set
+// explicitly, history and aliases are added only when their opt-in flags are on.
+func (c *Config) EnabledProviders() []string {
+	if len(c.ContextProviders) > 0 {
+		return c.ContextProviders
+	}
+
+	providers := append([]string{}, defaultProviders...)
+	if c.ShellAliases {
+		providers = append(providers, "aliases")
+	}
+	if c.ShellHistory {
+		providers = append(providers, "history")
+	}
+	return providers
+}
+
+// Default returns the built-in configuration, before any file or environment variable
+func Default() *Config {
+	return getDefaultConfig()
+}
+
 // getDefaultConfig returns a configuration with default values
 func getDefaultConfig() *Config {
 	return &Config{
-		Provider:        "ollama", // Default to ollama for local testing
-		Model:           "qwen2.5-coder:7b",
-		OllamaURL:       "http://localhost:11434",
-		MaxContextLines: 20,
+		Provider:         "ollama", // Default to ollama for local testing
+		Model:            "qwen2.5-coder:7b",
+		OllamaURL:        "http://localhost:11434",
+		MaxContextLines:  20,
+		HistoryEntries:   10,
+		HistoryMaxChars:  2000,
+		FilePreviewBytes: 4096,
+		ContextBudget:    6000,
+		MaxFixRounds:     3,
+		RecordHistory:    true,
+		ResponseCache:    true,
+		CacheTTL:         24 * time.Hour,
+		CacheMaxEntries:  500,
+		Timeout:          30 * time.Second,
+		Approve:          ApprovePrompt,
+		Color:            ColorAuto,
+		ProviderLimits: map[string]ProviderLimit{
+			"aliases": {Timeout: 3 * time.Second}, // sourcing rc files can be slow
+			"docker":  {Timeout: 500 * time.Millisecond},
+			"kube":    {Timeout: 200 * time.Millisecond},
+		},
 		DangerousPatterns: []string{
 			"rm -rf /",
 			"rm -rf /*",
@@ -96,11 +232,72 @@ func getDefaultConfig() *Config {
 	}
 }
 
-// getConfigPath returns the path to the config file
-func getConfigPath() (string, error) {
+// SystemPath is the machine-wide config file, read before the user's
+var SystemPath = "/etc/zchat/config.yaml"
+
+// ProjectFileName is the per-project config file, found by walking up from the working directory
+const ProjectFileName = ".zchat.yaml"
+
+// Files returns the config files that apply, from lowest to highest precedence: the system
+// config, the user config and the nearest project config. They need not exist.
+func Files() []string {
+	files := []string{SystemPath}
+	if path, err := Path(); err == nil {
+		files = append(files, path)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if path := FindProjectFile(cwd); path != "" {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// FindProjectFile returns the nearest .zchat.yaml in dir or its parents, or "" if there is none
+func FindProjectFile(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Path returns the path to the user's config file
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Dir returns the directory holding config.yaml and files shared alongside it, like snippets:
+// $XDG_CONFIG_HOME/zchat, or ~/.config/zchat
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "zchat"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "zchat"), nil
+}
+
+// StateDir returns the directory for zchat's runtime state: $XDG_STATE_HOME/zchat, or ~/.local/state/zchat
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "zchat"), nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".config", "zchat", "config.yaml"), nil
+	return filepath.Join(home, ".local", "state", "zchat"), nil
 }
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetDefaultConfig(t *testing.T) {
@@ -179,3 +181,191 @@ func TestDangerousPatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestValidate_InvalidRedactPattern(t *testing.T) {
+	cfg := &Config{
+		Provider:       "ollama",
+		Model:          "test",
+		RedactPatterns: []string{"([unclosed"},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Error("Expected error for invalid redaction pattern")
+	}
+}
+
+func TestEnabledProviders(t *testing.T) {
+	cfg := getDefaultConfig()
+
+	providers := strings.Join(cfg.EnabledProviders(), ",")
+	if providers != "files" {
+		t.Errorf("Expected default providers, got %s", providers)
+	}
+
+	cfg.ShellHistory = true
+	cfg.ShellAliases = true
+	providers = strings.Join(cfg.EnabledProviders(), ",")
+	if providers != "files,aliases,history" {
+		t.Errorf("Expected opt-in providers to be appended, got %s", providers)
+	}
+
+	cfg.ContextProviders = []string{"git", "files"}
+	providers = strings.Join(cfg.EnabledProviders(), ",")
+	if providers != "git,files" {
+		t.Errorf("Expected explicit provider list to win, got %s", providers)
+	}
+}
+
+func TestLoad_ProviderLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, ".config", "zchat")
+	os.MkdirAll(configDir, 0755)
+	configContent := `context_budget: 3000
+provider_limits:
+  git:
+    timeout: 500ms
+    max_chars: 800
+`
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0644)
+	t.Setenv("HOME", tmpDir)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.ContextBudget != 3000 {
+		t.Errorf("Expected ContextBudget 3000, got %d", cfg.ContextBudget)
+	}
+
+	if limit := cfg.ProviderLimits["git"]; limit.Timeout != 500*time.Millisecond || limit.MaxChars != 800 {
+		t.Errorf("Expected git limit 500ms/800, got %v", limit)
+	}
+
+	if cfg.ProviderLimits["aliases"].Timeout != 3*time.Second {
+		t.Error("Expected default aliases limit to be kept alongside configured limits")
+	}
+}
+
+func TestDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir() failed: %v", err)
+	}
+	if expected := filepath.Join(tmpDir, ".config", "zchat"); dir != expected {
+		t.Errorf("Expected %s, got %s", expected, dir)
+	}
+}
+
+func TestDir_XDGConfigHome(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir() failed: %v", err)
+	}
+	if expected := filepath.Join(tmpDir, "zchat"); dir != expected {
+		t.Errorf("Expected %s, got %s", expected, dir)
+	}
+}
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	os.MkdirAll(nested, 0755)
+
+	if got := FindProjectFile(nested); got != "" {
+		t.Errorf("Expected no project file, got %s", got)
+	}
+
+	projectPath := filepath.Join(root, "a", ProjectFileName)
+	os.WriteFile(projectPath, []byte("model: llama3\n"), 0644)
+	if got := FindProjectFile(nested); got != projectPath {
+		t.Errorf("Expected %s, got %s", projectPath, got)
+	}
+}
+
+func TestLoad_Layers(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "xdg"))
+	t.Setenv("ZCHAT_PROVIDER", "")
+	t.Setenv("OLLAMA_URL", "http://env:1234")
+
+	systemPath := filepath.Join(tmpDir, "etc", "config.yaml")
+	os.MkdirAll(filepath.Dir(systemPath), 0755)
+	os.WriteFile(systemPath, []byte("model: system\nmax_fix_rounds: 5\nollama_url: http://system:1\n"), 0644)
+	originalSystemPath := SystemPath
+	SystemPath = systemPath
+	defer func() { SystemPath = originalSystemPath }()
+
+	userPath := filepath.Join(tmpDir, "xdg", "zchat", "config.yaml")
+	os.MkdirAll(filepath.Dir(userPath), 0755)
+	os.WriteFile(userPath, []byte("model: user\ndangerous_patterns:\n  extend: [\"curl | sh\"]\n"), 0644)
+
+	projectDir := filepath.Join(tmpDir, "project")
+	os.MkdirAll(filepath.Join(projectDir, "sub"), 0755)
+	projectPath := filepath.Join(projectDir, ProjectFileName)
+	os.WriteFile(projectPath, []byte("model: project\nprovider: anthropic\ndangerous_patterns: [\"nothing\"]\n"), 0644)
+	t.Chdir(filepath.Join(projectDir, "sub"))
+
+	cfg, sources, err := LoadWithSources("")
+	if err != nil {
+		t.Fatalf("LoadWithSources() failed: %v", err)
+	}
+
+	if cfg.Model != "project" || sources.Get("model") != projectPath {
+		t.Errorf("Expected model from the project file, got %s from %s", cfg.Model, sources.Get("model"))
+	}
+	if cfg.MaxFixRounds != 5 || sources.Get("max_fix_rounds") != systemPath {
+		t.Errorf("Expected max_fix_rounds from the system file, got %d from %s", cfg.MaxFixRounds, sources.Get("max_fix_rounds"))
+	}
+	if cfg.OllamaURL != "http://env:1234" {
+		t.Errorf("Expected the environment to override the files, got %s", cfg.OllamaURL)
+	}
+	if cfg.Provider != "ollama" {
+		t.Errorf("Expected the project file not to change the provider, got %s", cfg.Provider)
+	}
+
+	defaults := getDefaultConfig().DangerousPatterns
+	if len(cfg.DangerousPatterns) != len(defaults)+1 || cfg.DangerousPatterns[len(defaults)] != "curl | sh" {
+		t.Errorf("Expected the defaults extended by the user file only, got %v", cfg.DangerousPatterns)
+	}
+	if expected := SourceDefault + ", extended by " + userPath; sources.Get("dangerous_patterns") != expected {
+		t.Errorf("Expected source %q, got %q", expected, sources.Get("dangerous_patterns"))
+	}
+}
+
+func TestValidate_Approve(t *testing.T) {
+	for _, approve := range []string{"", ApprovePrompt, ApproveReadOnly, ApproveAll, ApproveNever} {
+		cfg := &Config{Provider: "ollama", Approve: approve}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected approve %q to be valid, got %v", approve, err)
+		}
+	}
+
+	cfg := &Config{Provider: "ollama", Approve: "sometimes"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid approve policy")
+	}
+}
+
+func TestValidate_Color(t *testing.T) {
+	for _, color := range []string{"", ColorAuto, ColorAlways, ColorNever} {
+		cfg := &Config{Provider: "ollama", Color: color}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected color %q to be valid, got %v", color, err)
+		}
+	}
+
+	cfg := &Config{Provider: "ollama", Color: "rainbow"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid color setting")
+	}
+}
@@ -0,0 +1,559 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is something wrong in a config file, at a position in it
+type Problem struct {
+	Path    string
+	Line    int // 0 if unknown
+	Column  int // 0 if unknown
+	Message string
+	Warning bool // unknown keys only warn; everything else stops the config from loading
+}
+
+// String formats the problem as path:line:column: message, like a compiler error
+func (p Problem) String() string {
+	pos := p.Path
+	if p.Line > 0 {
+		pos += ":" + strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			pos += ":" + strconv.Itoa(p.Column)
+		}
+	}
+	if p.Warning {
+		return pos + ": warning: " + p.Message
+	}
+	return pos + ": " + p.Message
+}
+
+// Sources records where each setting got its value, by key. Keys that are missing kept
+// their built-in default.
+type Sources map[string]string
+
+// SourceDefault is the source of a setting nobody changed
+const SourceDefault = "default"
+
+// Get returns the source of key's value
+func (s Sources) Get(key string) string {
+	if source, ok := s[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// secretKeys hold credentials, which are masked whenever a config is shown
+var secretKeys = map[string]bool{"api_key": true}
+
+// yamlErrorPattern extracts the line from yaml's syntax errors
+var yamlErrorPattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// typeErrorLinePattern strips the position yaml puts in front of type errors, since
+// problems carry their own
+var typeErrorLinePattern = regexp.MustCompile(`^line \d+: `)
+
+// Keys returns the config file's keys, in the order of the Config fields
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := yamlKey(t.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// yamlKey returns the key a struct field is read from
+func yamlKey(f reflect.StructField) string {
+	key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+// field returns the field of cfg stored under key
+func (c *Config) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if yamlKey(v.Type().Field(i)) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// Value returns key's value as YAML, with secrets masked: a plain scalar for simple
+// settings, a block for lists and maps
+func (c *Config) Value(key string) (string, error) {
+	field, ok := c.field(key)
+	if !ok {
+		return "", unknownKeyError(key)
+	}
+	if secretKeys[key] {
+		return maskSecret(field.String()), nil
+	}
+
+	data, err := yaml.Marshal(field.Interface())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// Show renders the effective config as YAML, with each setting's source as a comment and
+// secrets masked
+func (c *Config) Show(sources Sources) (string, error) {
+	var node yaml.Node
+	if err := node.Encode(c); err != nil {
+		return "", err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		key.LineComment = sources.Get(key.Value)
+		if secretKeys[key.Value] {
+			value.Value = maskSecret(value.Value)
+		}
+	}
+	return encode(&node)
+}
+
+// maskSecret keeps just enough of a credential to tell which one it is
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 12 {
+		return "****"
+	}
+	return secret[:7] + "****"
+}
+
+// apply reads the settings in a config file's data into c, recording path as their source.
+// Only keys present in the file change; the first problem that isn't a warning is returned.
+func (c *Config) apply(path string, data []byte, sources Sources) error {
+	keys, problems := c.decodeFile(path, data)
+	return recordKeys(path, keys, problems, sources)
+}
+
+// recordKeys notes source as the origin of the decoded keys, unless decoding failed
+func recordKeys(source string, keys map[string]bool, problems []Problem, sources Sources) error {
+	for _, p := range problems {
+		if !p.Warning {
+			return errors.New(p.String())
+		}
+	}
+	for key, extended := range keys {
+		if extended {
+			sources[key] = sources.Get(key) + ", extended by " + source
+		} else {
+			sources[key] = source
+		}
+	}
+	return nil
+}
+
+// projectRestrictedKeys can't be set in a project's .zchat.yaml: a repository someone else
+// wrote must not send the context elsewhere, use another key or run commands without asking,
+// send more of the user's data than they opted into, nor pick or define a profile that does
+var projectRestrictedKeys = map[string]bool{
+	"provider": true, "api_key": true, "ollama_url": true, "approve": true, "exec_with_aliases": true,
+	"shell_history": true, "shell_aliases": true, "context_providers": true, "file_preview_bytes": true,
+	"profile": true, "profiles": true, "profile_rules": true,
+}
+
+// profileKeys choose and define profiles, so they can't be set within a profile
+var profileKeys = map[string]bool{"profile": true, "profiles": true, "profile_rules": true}
+
+// Where a mapping of settings comes from, which limits what it may set
+type scope int
+
+const (
+	scopeFile    scope = iota // the system or user config file
+	scopeProject              // a project's .zchat.yaml
+	scopeProfile              // a profile, within either of the above
+)
+
+// protectiveLists are lists a project's .zchat.yaml may only extend, so a repository can't
+// drop the checks the user relies on
+var protectiveLists = map[string]bool{"dangerous_patterns": true, "redact_patterns": true}
+
+// Ways a config file can combine a list with the one from the layers below
+const (
+	mergeExtend  = "extend"  // add to the list
+	mergeReplace = "replace" // replace the list, like a plain list does
+)
+
+// decodeFile decodes a config file into c key by key, so that every problem is reported
+// with its position rather than only the first. It returns the keys that were set, mapped
+// to whether they extended a list rather than replacing the value.
+func (c *Config) decodeFile(path string, data []byte) (map[string]bool, []Problem) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, []Problem{syntaxProblem(path, err)}
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil // an empty file
+	}
+
+	in := scopeFile
+	if filepath.Base(path) == ProjectFileName {
+		in = scopeProject
+	}
+	return c.decodeSettings(path, doc.Content[0], in)
+}
+
+// decodeSettings decodes a mapping of settings into c, like decodeFile. Problems are
+// reported against path, which is only used to describe them.
+func (c *Config) decodeSettings(path string, root *yaml.Node, in scope) (map[string]bool, []Problem) {
+	if root.Kind != yaml.MappingNode {
+		return nil, []Problem{{Path: path, Line: root.Line, Column: root.Column, Message: "expected a mapping of settings, like 'provider: ollama'"}}
+	}
+
+	keys := map[string]bool{}
+	var problems []Problem
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+		key := keyNode.Value
+		warn := func(message string) {
+			problems = append(problems, Problem{Path: path, Line: keyNode.Line, Column: keyNode.Column, Message: message, Warning: true})
+		}
+
+		field, ok := c.field(key)
+		if !ok {
+			message := fmt.Sprintf("unknown key %q", key)
+			if suggestion := closestKey(key); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			warn(message)
+			continue
+		}
+
+		merge := listMerge(valueNode)
+		switch {
+		case in == scopeProject && projectRestrictedKeys[key]:
+			warn(fmt.Sprintf("%s can't be set in a project's %s; ignored", key, ProjectFileName))
+			continue
+		case in == scopeProject && protectiveLists[key] && merge != mergeExtend:
+			warn(fmt.Sprintf("%s can only be extended in a project's %s, as in '%s: {extend: [...]}'; ignored", key, ProjectFileName, key))
+			continue
+		case in == scopeProfile && profileKeys[key]:
+			warn(fmt.Sprintf("%s can't be set within a profile; ignored", key))
+			continue
+		case key == "dangerous_patterns" && valueNode.Kind == yaml.SequenceNode:
+			warn("a plain list replaces the built-in dangerous_patterns; use {extend: [...]} to add to them, or {replace: [...]} to make replacing them explicit")
+		}
+
+		if err := decodeValue(field, valueNode); err != nil {
+			problems = append(problems, Problem{Path: path, Line: valueNode.Line, Column: valueNode.Column, Message: fmt.Sprintf("%s: %s", key, typeErrorMessage(err))})
+			continue
+		}
+		if key == "profiles" {
+			problems = append(problems, checkProfiles(path, valueNode)...)
+		}
+		keys[key] = merge == mergeExtend
+	}
+	return keys, problems
+}
+
+// listMerge returns how a list setting is combined with the layers below: "extend" or
+// "replace" if given as {extend: [...]} or {replace: [...]}, or "" for anything else
+func listMerge(node *yaml.Node) string {
+	if node.Kind != yaml.MappingNode || len(node.Content) != 2 {
+		return ""
+	}
+	switch node.Content[0].Value {
+	case mergeExtend, mergeReplace:
+		return node.Content[0].Value
+	}
+	return ""
+}
+
+// decodeValue decodes node into field. Lists may also be given as {extend: [...]}, which
+// appends the items not already present, or {replace: [...]}.
+func decodeValue(field reflect.Value, node *yaml.Node) error {
+	if field.Kind() != reflect.Slice || node.Kind != yaml.MappingNode {
+		return node.Decode(field.Addr().Interface())
+	}
+
+	merge := listMerge(node)
+	if merge == "" {
+		return fmt.Errorf("expected a list, {extend: [...]} or {replace: [...]}")
+	}
+	items := reflect.New(field.Type())
+	if err := node.Content[1].Decode(items.Interface()); err != nil {
+		return err
+	}
+
+	if merge == mergeReplace {
+		field.Set(items.Elem())
+		return nil
+	}
+	for i := 0; i < items.Elem().Len(); i++ {
+		item := items.Elem().Index(i)
+		present := false
+		for j := 0; j < field.Len(); j++ {
+			present = present || reflect.DeepEqual(field.Index(j).Interface(), item.Interface())
+		}
+		if !present {
+			field.Set(reflect.Append(field, item))
+		}
+	}
+	return nil
+}
+
+// syntaxProblem turns a yaml parse error into a problem, keeping the line if yaml gave one
+func syntaxProblem(path string, err error) Problem {
+	if m := yamlErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return Problem{Path: path, Line: line, Message: m[2]}
+	}
+	return Problem{Path: path, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+}
+
+// typeErrorMessage returns the reasons from a yaml decoding error without their positions
+func typeErrorMessage(err error) string {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err.Error()
+	}
+	reasons := make([]string, len(typeErr.Errors))
+	for i, reason := range typeErr.Errors {
+		reasons[i] = typeErrorLinePattern.ReplaceAllString(reason, "")
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// closestKey suggests the known key nearest to a misspelled one, if any is close enough
+func closestKey(key string) string {
+	best, bestDistance := "", 3
+	for _, known := range Keys() {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// Check reads the config file at path and reports every problem in it: syntax errors, values
+// of the wrong type and unknown keys. If those allow, the settings are also validated together.
+func Check(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := getDefaultConfig()
+	_, problems := cfg.decodeFile(path, data)
+	for _, p := range problems {
+		if !p.Warning {
+			return problems, nil
+		}
+	}
+
+	cfg.applyEnv(Sources{})
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, Problem{Path: path, Message: err.Error()})
+	}
+	return problems, nil
+}
+
+// Set changes key to value in the config file at path, creating the file if needed. The
+// value is parsed as YAML, so lists can be given as [a, b]. Comments and the order of the
+// other settings are kept, and nothing is written unless the result is a valid config.
+func Set(path, key, value string) error {
+	field, ok := getDefaultConfig().field(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return errors.New(syntaxProblem(path, err).String())
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping of settings", path)
+	}
+
+	// Parse the value and check it fits the setting before touching the file
+	valueNode, err := parseValue(key, value, reflect.New(field.Type()).Elem())
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			old := root.Content[i+1]
+			valueNode.LineComment, valueNode.FootComment = old.LineComment, old.FootComment
+			root.Content[i+1] = valueNode
+			replaced = true
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+	}
+
+	out, err := encode(&doc)
+	if err != nil {
+		return err
+	}
+
+	cfg := getDefaultConfig()
+	if err := cfg.apply(path, []byte(out), Sources{}); err != nil {
+		return err
+	}
+	cfg.applyEnv(Sources{})
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("not saved: %w", err)
+	}
+
+	return writeFile(path, []byte(out))
+}
+
+// parseValue parses value as YAML and checks that it fits field, the setting key's type
+func parseValue(key, value string, field reflect.Value) (*yaml.Node, error) {
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %s", key, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+	if len(parsed.Content) > 0 {
+		valueNode = parsed.Content[0]
+	}
+	if err := decodeValue(field, valueNode); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %s", key, typeErrorMessage(err))
+	}
+	return valueNode, nil
+}
+
+// Override sets key to value, written as in a config file, for command-line flags, and
+// records source as where it came from. Lists may be extended with {extend: [...]} like in a file.
+func (c *Config) Override(key, value, source string, sources Sources) error {
+	field, ok := c.field(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+	if !Overridable(key) {
+		return fmt.Errorf("%s can't be set for a single run", key)
+	}
+	node, err := parseValue(key, value, field)
+	if err != nil {
+		return err
+	}
+	return recordKeys(source, map[string]bool{key: listMerge(node) == mergeExtend}, nil, sources)
+}
+
+// IsSwitch reports whether key is an on/off setting, which a flag turns on without a value
+func IsSwitch(key string) bool {
+	field, ok := getDefaultConfig().field(key)
+	return ok && field.Kind() == reflect.Bool
+}
+
+// Overridable reports whether key may be set for a single run, as by a flag. Secrets would
+// end up in ps and the shell history, and profiles are only defined in config files.
+func Overridable(key string) bool {
+	return !secretKeys[key] && !profileKeys[key]
+}
+
+// Setting is a key and its value, for writing a new config file
+type Setting struct {
+	Key   string
+	Value any
+}
+
+// WriteFile writes a new config file at path holding settings, under a comment header
+func WriteFile(path, header string, settings []Setting) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range settings {
+		if _, ok := getDefaultConfig().field(s.Key); !ok {
+			return unknownKeyError(s.Key)
+		}
+		var value yaml.Node
+		if err := value.Encode(s.Value); err != nil {
+			return err
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s.Key}, &value)
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, HeadComment: header, Content: []*yaml.Node{root}}
+
+	out, err := encode(doc)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, []byte(out))
+}
+
+// encode renders a YAML node with two-space indentation
+func encode(node *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeFile writes a config file readable only by its owner, since it may hold an API key
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// unknownKeyError lists the valid keys, since a typo is the likely cause
+func unknownKeyError(key string) error {
+	keys := Keys()
+	sort.Strings(keys)
+	if suggestion := closestKey(key); suggestion != "" {
+		return fmt.Errorf("unknown key %q (did you mean %q?)", key, suggestion)
+	}
+	return fmt.Errorf("unknown key %q (valid keys: %s)", key, strings.Join(keys, ", "))
+}
@@ -0,0 +1,379 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file in a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	if len(keys) == 0 || keys[0] != "provider" {
+		t.Fatalf("Expected keys in field order starting with provider, got %v", keys)
+	}
+	for _, key := range []string{"api_key", "dangerous_patterns", "approve", "color"} {
+		found := false
+		for _, k := range keys {
+			found = found || k == key
+		}
+		if !found {
+			t.Errorf("Expected %s among the keys", key)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	path := writeConfig(t, `provider: ollama
+modle: llama3
+max_context_lines: lots
+cache_ttl: 2h
+`)
+
+	problems, err := Check(path)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", problems)
+	}
+
+	if got := problems[0].String(); got != path+`:2:1: warning: unknown key "modle" (did you mean "model"?)` {
+		t.Errorf("Unexpected unknown key warning: %s", got)
+	}
+	if got := problems[1].String(); !strings.HasPrefix(got, path+":3:20: max_context_lines: cannot unmarshal") {
+		t.Errorf("Expected a positioned type error, got %s", got)
+	}
+}
+
+func TestCheck_SyntaxError(t *testing.T) {
+	path := writeConfig(t, "provider: ollama\nmodel: [unclosed\n")
+
+	problems, err := Check(path)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Line == 0 || problems[0].Warning {
+		t.Errorf("Expected one syntax error with a line, got %v", problems)
+	}
+}
+
+func TestCheck_InvalidSetting(t *testing.T) {
+	t.Setenv("ZCHAT_PROVIDER", "")
+	path := writeConfig(t, "approve: sometimes\n")
+
+	problems, err := Check(path)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "invalid approve") {
+		t.Errorf("Expected the setting to be validated, got %v", problems)
+	}
+}
+
+func TestCheck_ListMerge(t *testing.T) {
+	defaults := getDefaultConfig().DangerousPatterns
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		warning  bool
+	}{
+		{"extend", "dangerous_patterns:\n  extend: [\"curl | sh\", \"rm -rf /\"]\n", append(append([]string{}, defaults...), "curl | sh"), false},
+		{"replace", "dangerous_patterns:\n  replace: [\"curl | sh\"]\n", []string{"curl | sh"}, false},
+		{"plain list", "dangerous_patterns: [\"curl | sh\"]\n", []string{"curl | sh"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := getDefaultConfig()
+			_, problems := cfg.decodeFile("config.yaml", []byte(tt.content))
+
+			if len(problems) > 0 != tt.warning {
+				t.Errorf("Expected warning %v, got %v", tt.warning, problems)
+			}
+			if strings.Join(cfg.DangerousPatterns, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected %v, got %v", tt.expected, cfg.DangerousPatterns)
+			}
+		})
+	}
+}
+
+func TestCheck_BadMerge(t *testing.T) {
+	path := writeConfig(t, "redact_patterns:\n  append: [\"x\"]\n")
+
+	problems, err := Check(path)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Warning || !strings.Contains(problems[0].Message, "{extend: [...]}") {
+		t.Errorf("Expected an error asking for extend or replace, got %v", problems)
+	}
+}
+
+func TestCheck_ProjectFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ProjectFileName)
+	os.WriteFile(path, []byte("approve: all\nredact_patterns: []\ndangerous_patterns:\n  extend: [\"terraform destroy\"]\n"), 0644)
+
+	cfg := getDefaultConfig()
+	keys, problems := cfg.decodeFile(path, mustRead(t, path))
+
+	if len(problems) != 2 || !strings.Contains(problems[0].Message, "approve can't be set") || !strings.Contains(problems[1].Message, "redact_patterns can only be extended") {
+		t.Errorf("Expected approve and redact_patterns to be ignored, got %v", problems)
+	}
+	if cfg.Approve != getDefaultConfig().Approve || len(cfg.RedactPatterns) != len(getDefaultConfig().RedactPatterns) {
+		t.Error("Expected the restricted settings to be left alone")
+	}
+	if !keys["dangerous_patterns"] || cfg.DangerousPatterns[len(cfg.DangerousPatterns)-1] != "terraform destroy" {
+		t.Errorf("Expected dangerous_patterns to be extended, got %v", cfg.DangerousPatterns)
+	}
+}
+
+func TestCheck_ProjectRestrictedKeys(t *testing.T) {
+	settings := map[string]string{
+		"provider":           "anthropic",
+		"api_key":            "sk-ant-xxx",
+		"ollama_url":         "http://attacker.example",
+		"approve":            "all",
+		"exec_with_aliases":  "true",
+		"shell_history":      "true",
+		"shell_aliases":      "true",
+		"context_providers":  "[files, history, aliases, docker, kube]",
+		"file_preview_bytes": "10000000",
+	}
+
+	for key, value := range settings {
+		path := filepath.Join(t.TempDir(), ProjectFileName)
+		os.WriteFile(path, []byte(key+": "+value+"\n"), 0644)
+
+		cfg := getDefaultConfig()
+		keys, problems := cfg.decodeFile(path, mustRead(t, path))
+
+		if keys[key] || len(problems) != 1 || !strings.Contains(problems[0].Message, key+" can't be set in a project's") {
+			t.Errorf("Expected %s to be ignored in %s, got %v", key, ProjectFileName, problems)
+		}
+		if !reflect.DeepEqual(cfg, getDefaultConfig()) {
+			t.Errorf("Expected %s to leave the config alone", key)
+		}
+	}
+}
+
+// mustRead reads a file the test wrote
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return data
+}
+
+func TestLoad_PositionedError(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	configDir := filepath.Join(tmpDir, ".config", "zchat")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("provider: ollama\nmax_fix_rounds: [1]\n"), 0644)
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "config.yaml:2:17: max_fix_rounds") {
+		t.Errorf("Expected an error pointing at line 2, got %v", err)
+	}
+}
+
+func TestLoadWithSources(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("ZCHAT_PROVIDER", "")
+	t.Setenv("OLLAMA_URL", "http://env:1234")
+	configDir := filepath.Join(tmpDir, ".config", "zchat")
+	os.MkdirAll(configDir, 0755)
+	configPath := filepath.Join(configDir, "config.yaml")
+	os.WriteFile(configPath, []byte("model: llama3\n"), 0644)
+
+	_, sources, err := LoadWithSources("")
+	if err != nil {
+		t.Fatalf("LoadWithSources() failed: %v", err)
+	}
+
+	if got := sources.Get("model"); got != configPath {
+		t.Errorf("Expected model from %s, got %s", configPath, got)
+	}
+	if got := sources.Get("ollama_url"); got != "env OLLAMA_URL" {
+		t.Errorf("Expected ollama_url from the environment, got %s", got)
+	}
+	if got := sources.Get("provider"); got != SourceDefault {
+		t.Errorf("Expected provider to be the default, got %s", got)
+	}
+}
+
+func TestShow(t *testing.T) {
+	cfg := getDefaultConfig()
+	cfg.APIKey = "sk-ant-api03-secretsecret"
+
+	out, err := cfg.Show(Sources{"api_key": "env ANTHROPIC_API_KEY"})
+	if err != nil {
+		t.Fatalf("Show() failed: %v", err)
+	}
+
+	if strings.Contains(out, "secretsecret") {
+		t.Error("Expected the API key to be masked")
+	}
+	if !strings.Contains(out, "api_key: sk-ant-****") || !strings.Contains(out, "# env ANTHROPIC_API_KEY") {
+		t.Errorf("Expected the masked key with its source, got:\n%s", out)
+	}
+	if !strings.Contains(out, "provider: ollama # default") {
+		t.Errorf("Expected defaults to be marked, got:\n%s", out)
+	}
+}
+
+func TestValue(t *testing.T) {
+	cfg := getDefaultConfig()
+	cfg.APIKey = "short"
+
+	if got, _ := cfg.Value("model"); got != "qwen2.5-coder:7b" {
+		t.Errorf("Expected model value, got %q", got)
+	}
+	if got, _ := cfg.Value("cache_ttl"); got != "24h0m0s" {
+		t.Errorf("Expected cache_ttl as a duration, got %q", got)
+	}
+	if got, _ := cfg.Value("api_key"); got != "****" {
+		t.Errorf("Expected masked API key, got %q", got)
+	}
+	if _, err := cfg.Value("modle"); err == nil || !strings.Contains(err.Error(), `did you mean "model"`) {
+		t.Errorf("Expected unknown key error with a suggestion, got %v", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Setenv("ZCHAT_PROVIDER", "")
+	path := writeConfig(t, `# my zchat settings
+provider: ollama
+model: qwen2.5-coder:7b # fast enough
+`)
+
+	if err := Set(path, "model", "llama3.1:8b"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := Set(path, "context_providers", "[files, git]"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := Set(path, "dangerous_patterns", `{extend: ["curl | sh"]}`); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, expected := range []string{"# my zchat settings", "model: llama3.1:8b # fast enough", "context_providers: [files, git]", `dangerous_patterns: {extend: ["curl | sh"]}`} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected %q in the config, got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestSet_Rejected(t *testing.T) {
+	t.Setenv("ZCHAT_PROVIDER", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	original := "provider: ollama\n"
+	path := writeConfig(t, original)
+
+	tests := []struct {
+		key, value, expected string
+	}{
+		{"modle", "x", "unknown key"},
+		{"max_fix_rounds", "many", "invalid value for max_fix_rounds"},
+		{"approve", "sometimes", "invalid approve"},
+		{"provider", "anthropic", "API key is required"},
+	}
+	for _, tt := range tests {
+		err := Set(path, tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Set(%s, %s): expected error containing %q, got %v", tt.key, tt.value, tt.expected, err)
+		}
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("Expected the file to be unchanged, got:\n%s", data)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zchat", "config.yaml")
+	err := WriteFile(path, "written by a test", []Setting{{"provider", "ollama"}, {"model", "llama3.1:8b"}})
+	if err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "# written by a test\n\nprovider: ollama\nmodel: llama3.1:8b\n" {
+		t.Errorf("Unexpected config file:\n%s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the config to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestOverride(t *testing.T) {
+	cfg := getDefaultConfig()
+	sources := Sources{}
+
+	for key, value := range map[string]string{
+		"model":              "llama3.1:8b",
+		"timeout":            "1m",
+		"shell_history":      "true",
+		"context_providers":  "[files, git]",
+		"dangerous_patterns": "{extend: [systemctl]}",
+	} {
+		if err := cfg.Override(key, value, "flag", sources); err != nil {
+			t.Errorf("Override(%s, %s) failed: %v", key, value, err)
+		}
+	}
+
+	if cfg.Model != "llama3.1:8b" || cfg.Timeout != time.Minute || !cfg.ShellHistory || len(cfg.ContextProviders) != 2 {
+		t.Errorf("Expected the overrides to be applied, got %+v", cfg)
+	}
+	if cfg.DangerousPatterns[len(cfg.DangerousPatterns)-1] != "systemctl" {
+		t.Errorf("Expected dangerous_patterns to be extended, got %v", cfg.DangerousPatterns)
+	}
+	if sources.Get("model") != "flag" || sources.Get("dangerous_patterns") != SourceDefault+", extended by flag" {
+		t.Errorf("Expected the flag as the source, got %v", sources)
+	}
+
+	if err := cfg.Override("max_fix_rounds", "lots", "flag", sources); err == nil || !strings.Contains(err.Error(), "invalid value for max_fix_rounds") {
+		t.Errorf("Expected an invalid value error, got %v", err)
+	}
+	if err := cfg.Override("modle", "x", "flag", sources); err == nil || !strings.Contains(err.Error(), `did you mean "model"`) {
+		t.Errorf("Expected an unknown key error, got %v", err)
+	}
+	if err := cfg.Override("api_key", "sk-ant-xxx", "flag", sources); err == nil || cfg.APIKey != "" {
+		t.Errorf("Expected api_key to be refused, got %v", err)
+	}
+}
+
+func TestOverridable(t *testing.T) {
+	for key, expected := range map[string]bool{"model": true, "shell_history": true, "api_key": false, "profiles": false, "profile_rules": false} {
+		if got := Overridable(key); got != expected {
+			t.Errorf("Overridable(%s) = %v, expected %v", key, got, expected)
+		}
+	}
+}
+
+func TestIsSwitch(t *testing.T) {
+	if !IsSwitch("verbose") || IsSwitch("model") || IsSwitch("nothing") {
+		t.Error("Expected only boolean settings to be switches")
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileRule picks a profile by working directory
+type ProfileRule struct {
+	Path    string `yaml:"path"`    // a glob matched against the working directory and its parents; ~/ is the home directory
+	Profile string `yaml:"profile"` // the profile to use there
+}
+
+// selectProfile returns the profile to use in dir and why: the one named by flag, then
+// $ZCHAT_PROFILE, then the first rule matching dir, then the profile setting
+func (c *Config) selectProfile(flag, dir string, sources Sources) (name, source string) {
+	if flag != "" {
+		return flag, "flag --profile"
+	}
+	if name := os.Getenv("ZCHAT_PROFILE"); name != "" {
+		return name, "env ZCHAT_PROFILE"
+	}
+	for _, rule := range c.ProfileRules {
+		if matchDir(rule.Path, dir) {
+			return rule.Profile, "rule " + rule.Path
+		}
+	}
+	return c.Profile, sources.Get("profile")
+}
+
+// useProfile overlays the named profile on c, recording source as the reason it was chosen
+func (c *Config) useProfile(name, source string, sources Sources) error {
+	node, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q (%s)", name, c.profileList())
+	}
+
+	keys, problems := c.decodeSettings("profile "+name, &node, scopeProfile)
+	if err := recordKeys("profile "+name, keys, problems, sources); err != nil {
+		return err
+	}
+	c.Profile = name
+	sources["profile"] = source
+	return nil
+}
+
+// profileList describes the defined profiles for error messages
+func (c *Config) profileList() string {
+	if len(c.Profiles) == 0 {
+		return "no profiles are defined"
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return "defined: " + strings.Join(names, ", ")
+}
+
+// checkProfiles decodes each profile in node onto the defaults, so that mistakes in a
+// profile are reported even when it isn't in use
+func checkProfiles(path string, node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		_, p := getDefaultConfig().decodeSettings(path, node.Content[i+1], scopeProfile)
+		problems = append(problems, p...)
+	}
+	return problems
+}
+
+// matchDir reports whether dir or one of its parents matches the glob pattern, so a rule
+// applies to a directory and everything below it. A trailing /** is optional.
+func matchDir(pattern, dir string) bool {
+	if rest, ok := strings.CutPrefix(pattern, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		pattern = filepath.Join(home, rest)
+	}
+	pattern = strings.TrimSuffix(pattern, "/**")
+
+	for {
+		if ok, _ := filepath.Match(pattern, dir); ok {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profilesConfig = `profile: local
+profiles:
+  local:
+    model: qwen2.5-coder:7b
+  paranoid:
+    approve: never
+    context_providers: [files]
+    dangerous_patterns:
+      extend: [systemctl]
+profile_rules:
+  - path: /srv/prod/**
+    profile: paranoid
+`
+
+// loadProfiles writes config as the user's config file and loads it with the given --profile
+func loadProfiles(t *testing.T, config, flag string) (*Config, Sources, error) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("ZCHAT_PROVIDER", "")
+	configDir := filepath.Join(tmpDir, ".config", "zchat")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(config), 0644)
+	t.Chdir(tmpDir)
+
+	return LoadWithSources(flag)
+}
+
+func TestMatchDir(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tests := []struct {
+		pattern  string
+		dir      string
+		expected bool
+	}{
+		{"/srv/prod", "/srv/prod", true},
+		{"/srv/prod/**", "/srv/prod/app/logs", true},
+		{"/srv/prod-*", "/srv/prod-eu/app", true},
+		{"/srv/prod", "/srv/production", false},
+		{"/srv/prod/**", "/srv", false},
+		{"~/work/*", filepath.Join(home, "work", "api", "cmd"), true},
+	}
+
+	for _, tt := range tests {
+		if got := matchDir(tt.pattern, tt.dir); got != tt.expected {
+			t.Errorf("matchDir(%q, %q) = %v, expected %v", tt.pattern, tt.dir, got, tt.expected)
+		}
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	cfg := &Config{Profile: "local", ProfileRules: []ProfileRule{{Path: "/srv/prod/**", Profile: "paranoid"}}}
+	sources := Sources{"profile": "config.yaml"}
+
+	tests := []struct {
+		name           string
+		flag           string
+		env            string
+		dir            string
+		expected       string
+		expectedSource string
+	}{
+		{"setting", "", "", "/home", "local", "config.yaml"},
+		{"rule", "", "", "/srv/prod/app", "paranoid", "rule /srv/prod/**"},
+		{"env", "", "cloud", "/srv/prod/app", "cloud", "env ZCHAT_PROFILE"},
+		{"flag", "local", "cloud", "/srv/prod/app", "local", "flag --profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ZCHAT_PROFILE", tt.env)
+			name, source := cfg.selectProfile(tt.flag, tt.dir, sources)
+			if name != tt.expected || source != tt.expectedSource {
+				t.Errorf("Expected %s from %s, got %s from %s", tt.expected, tt.expectedSource, name, source)
+			}
+		})
+	}
+}
+
+func TestLoad_Profile(t *testing.T) {
+	t.Setenv("ZCHAT_PROFILE", "")
+	t.Setenv("OLLAMA_URL", "")
+
+	cfg, sources, err := loadProfiles(t, profilesConfig, "paranoid")
+	if err != nil {
+		t.Fatalf("LoadWithSources() failed: %v", err)
+	}
+
+	if cfg.Profile != "paranoid" || sources.Get("profile") != "flag --profile" {
+		t.Errorf("Expected profile paranoid from the flag, got %s from %s", cfg.Profile, sources.Get("profile"))
+	}
+	if cfg.Approve != ApproveNever || sources.Get("approve") != "profile paranoid" {
+		t.Errorf("Expected approve never from the profile, got %s from %s", cfg.Approve, sources.Get("approve"))
+	}
+	if len(cfg.ContextProviders) != 1 || cfg.ContextProviders[0] != "files" {
+		t.Errorf("Expected the profile's context providers, got %v", cfg.ContextProviders)
+	}
+	if patterns := cfg.DangerousPatterns; patterns[len(patterns)-1] != "systemctl" || len(patterns) != len(getDefaultConfig().DangerousPatterns)+1 {
+		t.Errorf("Expected the defaults extended by the profile, got %v", patterns)
+	}
+}
+
+func TestLoad_ProfileEnvOverrides(t *testing.T) {
+	t.Setenv("ZCHAT_PROFILE", "")
+	t.Setenv("OLLAMA_URL", "http://env:1234")
+
+	cfg, _, err := loadProfiles(t, "profiles:\n  remote:\n    ollama_url: http://gpu-box:11434\nprofile: remote\n", "")
+	if err != nil {
+		t.Fatalf("LoadWithSources() failed: %v", err)
+	}
+	if cfg.OllamaURL != "http://env:1234" {
+		t.Errorf("Expected the environment to override the profile, got %s", cfg.OllamaURL)
+	}
+}
+
+func TestLoad_UnknownProfile(t *testing.T) {
+	t.Setenv("ZCHAT_PROFILE", "clod")
+
+	_, _, err := loadProfiles(t, profilesConfig, "")
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "clod" (defined: local, paranoid)`) {
+		t.Errorf("Expected an unknown profile error listing the profiles, got %v", err)
+	}
+}
+
+func TestCheck_Profiles(t *testing.T) {
+	path := writeConfig(t, `profiles:
+  fast:
+    modle: llama3
+    profile: other
+  slow:
+    max_fix_rounds: lots
+`)
+
+	problems, err := Check(path)
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+
+	expected := []string{
+		path + `:3:5: warning: unknown key "modle" (did you mean "model"?)`,
+		path + ":4:5: warning: profile can't be set within a profile; ignored",
+		path + ":6:21: max_fix_rounds: cannot unmarshal",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, p := range problems {
+		if !strings.HasPrefix(p.String(), expected[i]) {
+			t.Errorf("Expected %q, got %q", expected[i], p.String())
+		}
+	}
+}
+
+func TestCheck_ProjectProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ProjectFileName)
+	os.WriteFile(path, []byte("profile: cloud\n"), 0644)
+
+	cfg := getDefaultConfig()
+	_, problems := cfg.decodeFile(path, mustRead(t, path))
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "profile can't be set in a project's") {
+		t.Errorf("Expected the profile to be ignored, got %v", problems)
+	}
+	if cfg.Profile != "" {
+		t.Errorf("Expected no profile, got %s", cfg.Profile)
+	}
+}
@@ -0,0 +1,307 @@
+package context
+
+import (
+	"bufio"
+	stdcontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	aliasCacheTTL      = 24 * time.Hour
+	maxAliases         = 60
+	maxFunctions       = 30
+	maxDefinitionChars = 80
+	aliasSection       = "@@ZCHAT_ALIASES"
+	aliasCacheVersion  = 2 // 1 stored shortened definitions
+	functionSection    = "@@ZCHAT_FUNCTIONS"
+)
+
+// ShellAlias is an alias or function defined in the user's shell configuration
+type ShellAlias struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
+// AliasReader captures aliases and functions by running the user's shell with its rc files sourced
+type AliasReader struct {
+	cacheDir string
+}
+
+// AliasProvider exposes the user's aliases and functions as a context section
+type AliasProvider struct {
+	reader *AliasReader
+	// available is set when commands are executed with the user's aliases loaded
+	available bool
+}
+
+type aliasCache struct {
+	Key       string       `json:"key"`
+	Aliases   []ShellAlias `json:"aliases"`
+	Functions []ShellAlias `json:"functions"`
+}
+
+// NewAliasReader creates an alias reader that caches results in cacheDir
+func NewAliasReader(cacheDir string) *AliasReader {
+	return &AliasReader{
+		cacheDir: cacheDir,
+	}
+}
+
+// Name returns the provider name
+func (p *AliasProvider) Name() string {
+	return "aliases"
+}
+
+// Provide lists aliases and functions, noting whether they are loaded when the command runs
+func (p *AliasProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	aliases, functions, err := p.reader.Read(ctx, env.Shell)
+	if err != nil {
+		return nil, err
+	}
+
+	section := &Section{
+		Title: "USER ALIASES AND FUNCTIONS (NOT loaded when the command runs; use the underlying commands, and note that names like ls may behave differently in the user's own shell)",
+	}
+	if p.available {
+		section.Title = "USER ALIASES AND FUNCTIONS (loaded when the command runs; prefer them where they fit)"
+	}
+
+	section.Items = aliasItems(aliases, functions)
+	return section, nil
+}
+
+// aliasItems lists aliases and functions for the prompt, shortening long definitions and
+// keeping the first few, so that a large rc file can't crowd out the rest of the context
+func aliasItems(aliases, functions []ShellAlias) []string {
+	var items []string
+	for _, alias := range aliases[:min(len(aliases), maxAliases)] {
+		items = append(items, fmt.Sprintf("alias %s=%s", alias.Name, shorten(alias.Definition)))
+	}
+	for _, function := range functions[:min(len(functions), maxFunctions)] {
+		items = append(items, fmt.Sprintf("function %s: %s", function.Name, shorten(function.Definition)))
+	}
+	return items
+}
+
+// DefaultCacheDir returns $XDG_CACHE_HOME/zchat, or ~/.cache/zchat
+func DefaultCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "zchat")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cache", "zchat")
+}
+
+// Read returns the aliases and functions defined for shell, with their whole definitions, using
+// the cache while rc files are unchanged
+func (a *AliasReader) Read(ctx stdcontext.Context, shell string) ([]ShellAlias, []ShellAlias, error) {
+	script, args := aliasScript(shell)
+	if script == "" {
+		return nil, nil, fmt.Errorf("alias capture is not supported for %s", shell)
+	}
+
+	key := aliasCacheKey(shell)
+	cachePath := filepath.Join(a.cacheDir, "aliases-"+key[:16]+".json")
+	if a.cacheDir != "" {
+		if cached, ok := readAliasCache(cachePath, key); ok {
+			return cached.Aliases, cached.Functions, nil
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, shell, append(args, script)...)
+	cmd.WaitDelay = time.Second
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, nil, fmt.Errorf("timed out reading aliases from %s", shell)
+	}
+	if err != nil && len(output) == 0 {
+		return nil, nil, err
+	}
+
+	aliases, functions := parseAliasOutput(string(output), ShellName(shell))
+
+	if a.cacheDir != "" {
+		writeAliasCache(cachePath, aliasCache{Key: key, Aliases: aliases, Functions: functions})
+	}
+
+	return aliases, functions, nil
+}
+
+// aliasScript returns a script that prints aliases and functions between section markers,
+// and the shell flags needed for the rc files to be sourced
+func aliasScript(shell string) (string, []string) {
+	switch ShellName(shell) {
+	case "zsh":
+		return `source "${ZDOTDIR:-$HOME}/.zshrc" >/dev/null 2>&1 </dev/null
+echo ` + aliasSection + `
+alias
+echo ` + functionSection + `
+for f in ${(k)functions}; do
+  [[ $f == _* || $f == prompt_* ]] && continue
+  print -r -- "$f=${${functions[$f]//$'\n'/; }//$'\t'/}"
+done`, []string{"-c"}
+	case "bash":
+		// .bashrc usually returns early unless the shell is interactive
+		return `echo ` + aliasSection + `
+alias
+echo ` + functionSection + `
+for f in $(compgen -A function); do
+  case $f in _*) continue;; esac
+  printf '%s=%s\n' "$f" "$(declare -f "$f" | tail -n +3 | tr -s ' \n' ' ')"
+done`, []string{"-i", "-c"}
+	case "fish":
+		return `echo ` + aliasSection + `
+alias
+echo ` + functionSection + `
+for f in (functions -n | string split ', ')
+  string match -q -r '^_|^fish_' -- $f; and continue
+  echo $f=(functions --details --verbose $f)[5]
+end`, []string{"-i", "-c"}
+	default:
+		return "", nil
+	}
+}
+
+// parseAliasOutput splits the capture script output into aliases and functions
+func parseAliasOutput(output, shellName string) ([]ShellAlias, []ShellAlias) {
+	var aliases, functions []ShellAlias
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch line {
+		case aliasSection, functionSection:
+			section = line
+			continue
+		}
+
+		switch section {
+		case aliasSection:
+			if alias, ok := parseAliasLine(line, shellName); ok {
+				aliases = append(aliases, alias)
+			}
+		case functionSection:
+			name, body, ok := strings.Cut(line, "=")
+			if ok && name != "" {
+				functions = append(functions, ShellAlias{Name: name, Definition: strings.TrimSpace(body)})
+			}
+		}
+	}
+
+	return aliases, functions
+}
+
+// parseAliasLine parses one line of `alias` output: "name=value" (zsh), "alias name='value'" (bash)
+// or "alias name 'value'" (fish)
+func parseAliasLine(line, shellName string) (ShellAlias, bool) {
+	line = strings.TrimPrefix(line, "alias ")
+
+	sep := "="
+	if shellName == "fish" {
+		sep = " "
+	}
+
+	name, value, ok := strings.Cut(line, sep)
+	if !ok || name == "" {
+		return ShellAlias{}, false
+	}
+
+	return ShellAlias{Name: name, Definition: unquoteShell(value)}, true
+}
+
+// unquoteShell removes single-quote quoting as printed by `alias`, including the '\” idiom
+func unquoteShell(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+	return strings.ReplaceAll(value[1:len(value)-1], `'\''`, `'`)
+}
+
+// shorten truncates long definitions so a few large functions can't crowd out the prompt
+func shorten(definition string) string {
+	if len(definition) > maxDefinitionChars {
+		return definition[:maxDefinitionChars] + "…"
+	}
+	return definition
+}
+
+// aliasCacheKey fingerprints the shell binary and the modification times of its rc files
+func aliasCacheKey(shell string) string {
+	h := sha256.New()
+	fmt.Fprintln(h, aliasCacheVersion, shell)
+
+	for _, rc := range rcFiles(shell) {
+		if info, err := os.Stat(rc); err == nil {
+			fmt.Fprintln(h, rc, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// rcFiles lists the startup files whose changes invalidate cached aliases
+func rcFiles(shell string) []string {
+	home, _ := os.UserHomeDir()
+
+	switch ShellName(shell) {
+	case "zsh":
+		zdotdir := os.Getenv("ZDOTDIR")
+		if zdotdir == "" {
+			zdotdir = home
+		}
+		return []string{filepath.Join(zdotdir, ".zshenv"), filepath.Join(zdotdir, ".zshrc")}
+	case "bash":
+		return []string{filepath.Join(home, ".bashrc"), filepath.Join(home, ".bash_aliases")}
+	case "fish":
+		return []string{
+			filepath.Join(home, ".config", "fish", "config.fish"),
+			filepath.Join(home, ".config", "fish", "functions"),
+		}
+	default:
+		return nil
+	}
+}
+
+// readAliasCache loads a cache entry if it matches key and hasn't expired
+func readAliasCache(path, key string) (*aliasCache, bool) {
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > aliasCacheTTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cached aliasCache
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Key != key {
+		return nil, false
+	}
+
+	return &cached, true
+}
+
+// writeAliasCache stores captured aliases; failures are ignored since the cache is only an optimization
+func writeAliasCache(path string, cached aliasCache) {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,119 @@
+package context
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAliasOutput_Zsh(t *testing.T) {
+	output := "noise from rc\n" + aliasSection + "\nk=kubectl\nls='eza --icons'\nquote='echo '\\''hi'\\'''\n" +
+		functionSection + "\nmkcd=mkdir -p \"$1\"; cd \"$1\"\n"
+
+	aliases, functions := parseAliasOutput(output, "zsh")
+
+	expected := []ShellAlias{{"k", "kubectl"}, {"ls", "eza --icons"}, {"quote", "echo 'hi'"}}
+	if len(aliases) != len(expected) {
+		t.Fatalf("Expected %d aliases, got %v", len(expected), aliases)
+	}
+	for i, alias := range expected {
+		if aliases[i] != alias {
+			t.Errorf("Expected %v, got %v", alias, aliases[i])
+		}
+	}
+
+	if len(functions) != 1 || functions[0].Name != "mkcd" || !strings.HasPrefix(functions[0].Definition, "mkdir -p") {
+		t.Errorf("Unexpected functions: %v", functions)
+	}
+}
+
+func TestParseAliasOutput_BashAndFish(t *testing.T) {
+	aliases, _ := parseAliasOutput(aliasSection+"\nalias gco='git checkout'\n", "bash")
+	if len(aliases) != 1 || aliases[0] != (ShellAlias{"gco", "git checkout"}) {
+		t.Errorf("Unexpected bash aliases: %v", aliases)
+	}
+
+	aliases, _ = parseAliasOutput(aliasSection+"\nalias dcup 'docker compose up -d'\n", "fish")
+	if len(aliases) != 1 || aliases[0] != (ShellAlias{"dcup", "docker compose up -d"}) {
+		t.Errorf("Unexpected fish aliases: %v", aliases)
+	}
+}
+
+func TestAliasItems_TruncatesLongDefinitions(t *testing.T) {
+	_, functions := parseAliasOutput(functionSection+"\nbig="+strings.Repeat("x", 500)+"\n", "zsh")
+
+	if len(functions) != 1 || len(functions[0].Definition) != 500 {
+		t.Fatalf("Expected the whole definition to be read, got %v", functions)
+	}
+
+	items := aliasItems(nil, functions)
+	if len(items) != 1 || len(items[0]) > len("function big: ")+maxDefinitionChars+len("…") {
+		t.Errorf("Expected definition to be truncated in the prompt, got %v", items)
+	}
+}
+
+func TestAliasReader_Bash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	rc := "alias k=kubectl\nmkcd() { mkdir -p \"$1\" && cd \"$1\"; }\n"
+	os.WriteFile(filepath.Join(home, ".bashrc"), []byte(rc), 0644)
+
+	cacheDir := t.TempDir()
+	aliases, functions, err := NewAliasReader(cacheDir).Read(context.Background(), bash)
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+
+	if len(aliases) != 1 || aliases[0] != (ShellAlias{"k", "kubectl"}) {
+		t.Errorf("Expected alias k=kubectl, got %v", aliases)
+	}
+
+	if len(functions) != 1 || functions[0].Name != "mkcd" {
+		t.Errorf("Expected function mkcd, got %v", functions)
+	}
+
+	// A second read should be served from the cache, even if the shell can no longer run
+	entries, _ := os.ReadDir(cacheDir)
+	if len(entries) != 1 {
+		t.Fatalf("Expected one cache file, got %d", len(entries))
+	}
+
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	cached, _, err := NewAliasReader(cacheDir).Read(expired, bash)
+	if err != nil || len(cached) != 1 {
+		t.Errorf("Expected cached aliases, got %v (%v)", cached, err)
+	}
+}
+
+func TestAliasReader_CacheInvalidatedByRcChange(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	rc := filepath.Join(home, ".bashrc")
+	os.WriteFile(rc, []byte("alias a=b\n"), 0644)
+
+	before := aliasCacheKey("/bin/bash")
+
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(rc, later, later)
+
+	if aliasCacheKey("/bin/bash") == before {
+		t.Error("Expected cache key to change when the rc file changes")
+	}
+}
+
+func TestAliasReader_UnsupportedShell(t *testing.T) {
+	_, _, err := NewAliasReader("").Read(context.Background(), "/usr/bin/nu")
+	if err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+}
@@ -1,8 +1,11 @@
 package context
 
 import (
+	stdcontext "context"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
@@ -13,6 +16,9 @@ type SystemContext struct {
 	Shell      string
 	OS         string
 	Arch       string
+	Samples    []FileSample
+	ContextOff bool      // set when a .zchatignore disables collection for this directory
+	Sections   []Section // output of the configured context providers, in render order
 }
 
 type Collector interface {
@@ -20,48 +26,110 @@ type Collector interface {
 }
 
 type DefaultCollector struct {
+	providers []registeredProvider
+	budget    int
+}
+
+// Summary describes where a request was made, e.g. "/home/user/app (bash, linux/amd64)"
+func (s *SystemContext) Summary() string {
+	dir := s.WorkingDir
+	if s.ContextOff {
+		dir = "(withheld)"
+	}
+	return fmt.Sprintf("%s (%s, %s/%s)", dir, ShellName(s.Shell), s.OS, s.Arch)
+}
+
+// FilesProvider lists the visible files in the working directory
+type FilesProvider struct {
 	maxFiles int
 }
 
 // NewDefaultCollector creates a new collector with file limit
 func NewDefaultCollector(maxFiles int) *DefaultCollector {
+	c := &DefaultCollector{
+		budget: DefaultContextBudget,
+	}
+	c.AddProvider(&FilesProvider{maxFiles: maxFiles}, ProviderLimit{})
+	return c
+}
+
+// NewProviderCollector creates a collector with no providers that shares budget characters
+// between the providers later registered with AddProvider
+func NewProviderCollector(budget int) *DefaultCollector {
 	return &DefaultCollector{
-		maxFiles: maxFiles,
+		budget: budget,
 	}
 }
 
+// AddProvider registers a context provider; sections are rendered in registration order
+func (c *DefaultCollector) AddProvider(provider ContextProvider, limit ProviderLimit) {
+	c.providers = append(c.providers, registeredProvider{provider: provider, limit: limit})
+}
+
 // Collect gathers system context information
 func (c *DefaultCollector) Collect() (*SystemContext, error) {
-	ctx := &SystemContext{}
+	ctx := &SystemContext{Files: []string{}}
 
 	// Get current working directory
 	wd, err := os.Getwd()
 	if err != nil {
 		return nil, err
 	}
-	ctx.WorkingDir = wd
-
-	// Get file listing
-	files, _ := c.getFileList() // Don't fail if ls fails
-	ctx.Files = files
 
-	// Get shell (default to zsh if not set)
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "/bin/zsh"
-	}
+	// Get the invoking shell (parent process, then $SHELL, then whatever exists)
+	shell := DetectShell()
 	ctx.Shell = shell
 
 	// Get OS and architecture
 	ctx.OS = runtime.GOOS
 	ctx.Arch = runtime.GOARCH
 
+	// Honor .zchatignore files; fail closed if they can't be read
+	ignore, err := LoadIgnoreList(wd)
+	if err != nil {
+		return nil, err
+	}
+	if ignore.ContextOff() {
+		ctx.ContextOff = true
+		return ctx, nil
+	}
+	ctx.WorkingDir = wd
+
+	// Run providers concurrently, then fit their output into the budget
+	env := &ProviderEnv{WorkingDir: wd, Shell: shell, Ignore: ignore}
+	sections := runProviders(c.providers, env)
+	allocateBudget(sections, c.budget)
+
+	// The file listing is rendered as part of the core system context
+	for _, section := range sections {
+		if section.Provider == "files" {
+			ctx.Files = section.Items
+			continue
+		}
+		ctx.Sections = append(ctx.Sections, section)
+	}
+
 	return ctx, nil
 }
 
-// getFileList retrieves a list of files in the current directory
-func (c *DefaultCollector) getFileList() ([]string, error) {
-	cmd := exec.Command("ls", "-1")
+// Name returns the provider name
+func (p *FilesProvider) Name() string {
+	return "files"
+}
+
+// Provide lists visible, non-ignored files in the working directory
+func (p *FilesProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	files, err := listFiles(ctx, env.WorkingDir, env.Ignore, p.maxFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &Section{Title: "Available Files", Items: files}, nil
+}
+
+// listFiles runs ls in dir and returns up to maxFiles visible entries
+func listFiles(ctx stdcontext.Context, dir string, ignore *IgnoreList, maxFiles int) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "ls", "-1")
+	cmd.Dir = dir
 	output, err := cmd.Output()
 	if err != nil {
 		return []string{}, err
@@ -77,8 +145,15 @@ func (c *DefaultCollector) getFileList() ([]string, error) {
 		if line == "" || strings.HasPrefix(line, ".") {
 			continue
 		}
+		if ignore != nil {
+			path := filepath.Join(dir, line)
+			info, err := os.Stat(path)
+			if ignore.Ignored(path, err == nil && info.IsDir()) {
+				continue
+			}
+		}
 		files = append(files, line)
-		if len(files) >= c.maxFiles {
+		if len(files) >= maxFiles {
 			break
 		}
 	}
@@ -1,16 +1,18 @@
 package context
 
 import (
+	stdcontext "context"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 )
 
 func TestNewDefaultCollector(t *testing.T) {
 	collector := NewDefaultCollector(10)
 
-	if collector.maxFiles != 10 {
-		t.Errorf("Expected maxFiles 10, got %d", collector.maxFiles)
+	if len(collector.providers) != 1 || collector.providers[0].provider.Name() != "files" {
+		t.Errorf("Expected only the files provider, got %v", collector.providers)
 	}
 }
 
@@ -68,26 +70,34 @@ func TestCollect_FileList(t *testing.T) {
 	}
 }
 
-func TestGetFileList_MaxFilesLimit(t *testing.T) {
-	collector := NewDefaultCollector(3)
-	files, err := collector.getFileList()
+func TestFilesProvider_MaxFilesLimit(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"} {
+		writeFile(t, dir, name, nil)
+	}
 
-	// err is ok if ls fails in test environment
-	if err == nil {
-		if len(files) > 3 {
-			t.Errorf("Expected at most 3 files, got %d", len(files))
-		}
+	section, err := (&FilesProvider{maxFiles: 3}).Provide(stdcontext.Background(), &ProviderEnv{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Provide() failed: %v", err)
+	}
+
+	if strings.Join(section.Items, ",") != "a.txt,b.txt,c.txt" {
+		t.Errorf("Expected the first 3 files, got %v", section.Items)
 	}
 }
 
-func TestGetFileList_HiddenFilesFiltered(t *testing.T) {
-	collector := NewDefaultCollector(100)
-	files, _ := collector.getFileList()
+func TestFilesProvider_HiddenFilesFiltered(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", nil)
+	writeFile(t, dir, "main.go", nil)
 
-	for _, file := range files {
-		if len(file) > 0 && file[0] == '.' {
-			t.Errorf("Hidden file should be filtered out: %s", file)
-		}
+	section, err := (&FilesProvider{maxFiles: 100}).Provide(stdcontext.Background(), &ProviderEnv{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Provide() failed: %v", err)
+	}
+
+	if strings.Join(section.Items, ",") != "main.go" {
+		t.Errorf("Expected hidden files to be filtered out, got %v", section.Items)
 	}
 }
 
@@ -107,8 +117,20 @@ func TestCollect_ShellDefault(t *testing.T) {
 		t.Fatalf("Collect() failed: %v", err)
 	}
 
-	// Should default to /bin/zsh
-	if ctx.Shell != "/bin/zsh" {
-		t.Errorf("Expected default shell '/bin/zsh', got '%s'", ctx.Shell)
+	// Should resolve to a shell binary that actually exists
+	if _, err := os.Stat(ctx.Shell); err != nil {
+		t.Errorf("Expected an existing shell binary, got '%s': %v", ctx.Shell, err)
+	}
+}
+
+func TestSystemContext_Summary(t *testing.T) {
+	sysCtx := &SystemContext{WorkingDir: "/home/user/app", Shell: "/usr/bin/bash", OS: "linux", Arch: "amd64"}
+	if got := sysCtx.Summary(); got != "/home/user/app (bash, linux/amd64)" {
+		t.Errorf("Unexpected summary: %s", got)
+	}
+
+	sysCtx.ContextOff = true
+	if got := sysCtx.Summary(); got != "(withheld) (bash, linux/amd64)" {
+		t.Errorf("Expected withheld directory, got %s", got)
 	}
 }
@@ -0,0 +1,124 @@
+package context
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const maxContainers = 20
+
+// DockerProvider lists containers from a local Docker or Podman API socket
+type DockerProvider struct {
+	socketPath string
+}
+
+type dockerContainer struct {
+	Names  []string `json:"Names"`
+	Image  string   `json:"Image"`
+	State  string   `json:"State"`
+	Status string   `json:"Status"`
+}
+
+// NewDockerProvider creates a provider for the given socket, or the first local socket found if empty
+func NewDockerProvider(socketPath string) *DockerProvider {
+	if socketPath == "" {
+		socketPath = findDockerSocket()
+	}
+	return &DockerProvider{socketPath: socketPath}
+}
+
+// Name returns the provider name
+func (p *DockerProvider) Name() string {
+	return "docker"
+}
+
+// Provide reports container names, images and status
+func (p *DockerProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	if p.socketPath == "" {
+		return nil, nil // No container runtime
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx stdcontext.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", p.socketPath)
+			},
+		},
+	}
+
+	// The host is ignored by the dialer but required in the URL
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://docker/containers/json?all=1", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("container API returned status %d", resp.StatusCode)
+	}
+
+	var containers []dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("failed to decode container list: %w", err)
+	}
+
+	section := &Section{Title: "CONTAINERS (name, image, state)"}
+	for _, c := range containers {
+		if len(section.Items) >= maxContainers {
+			break
+		}
+		name := ""
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		section.Items = append(section.Items, fmt.Sprintf("%s  image=%s  %s (%s)", name, c.Image, c.State, c.Status))
+		section.Identity = append(section.Identity, name+" "+c.Image)
+	}
+	if len(containers) == 0 {
+		section.Items = append(section.Items, "(no containers)")
+	}
+	// Which containers exist matters, not their state or the order they are listed in
+	slices.Sort(section.Identity)
+
+	return section, nil
+}
+
+// findDockerSocket locates a Docker or Podman API socket, honoring DOCKER_HOST
+func findDockerSocket() string {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		if path, ok := strings.CutPrefix(host, "unix://"); ok {
+			return path
+		}
+		return "" // Remote daemons are out of scope
+	}
+
+	candidates := []string{"/var/run/docker.sock"}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".docker", "run", "docker.sock"))
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			return path
+		}
+	}
+
+	return ""
+}
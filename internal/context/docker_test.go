@@ -0,0 +1,115 @@
+package context
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDockerServer serves handler on a Unix socket and returns the socket path
+func fakeDockerServer(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "zchat-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	server := &http.Server{Handler: handler}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return socket
+}
+
+func TestDockerProvider(t *testing.T) {
+	socket := fakeDockerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/json" || r.URL.Query().Get("all") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"Names": ["/api"], "Image": "acme/api:1.4", "State": "running", "Status": "Up 2 hours"},
+			{"Names": ["/worker"], "Image": "acme/worker:1.4", "State": "exited", "Status": "Exited (1) 5 minutes ago"}
+		]`))
+	})
+
+	section, err := NewDockerProvider(socket).Provide(context.Background(), &ProviderEnv{})
+	if err != nil || section == nil {
+		t.Fatalf("Expected section, got %v, %v", section, err)
+	}
+
+	if len(section.Items) != 2 {
+		t.Fatalf("Expected 2 containers, got %v", section.Items)
+	}
+	if section.Items[0] != "api  image=acme/api:1.4  running (Up 2 hours)" {
+		t.Errorf("Unexpected item: %q", section.Items[0])
+	}
+	if !strings.Contains(section.Items[1], "exited") {
+		t.Errorf("Expected exited state, got %q", section.Items[1])
+	}
+	if !slices.Equal(section.Identity, []string{"api acme/api:1.4", "worker acme/worker:1.4"}) {
+		t.Errorf("Expected container names and images as identity, got %q", section.Identity)
+	}
+}
+
+func TestDockerProvider_Timeout(t *testing.T) {
+	socket := fakeDockerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := NewDockerProvider(socket).Provide(ctx, &ProviderEnv{}); err == nil {
+		t.Error("Expected error when the API does not answer in time")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected provider to honor the context deadline, took %v", time.Since(start))
+	}
+}
+
+func TestDockerProvider_ErrorStatus(t *testing.T) {
+	socket := fakeDockerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	})
+
+	if _, err := NewDockerProvider(socket).Provide(context.Background(), &ProviderEnv{}); err == nil {
+		t.Error("Expected error for non-200 response")
+	}
+}
+
+func TestDockerProvider_NoSocket(t *testing.T) {
+	section, err := (&DockerProvider{}).Provide(context.Background(), &ProviderEnv{})
+	if section != nil || err != nil {
+		t.Errorf("Expected nothing without a socket, got %v, %v", section, err)
+	}
+}
+
+func TestFindDockerSocket_DockerHost(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///tmp/custom.sock")
+	if got := findDockerSocket(); got != "/tmp/custom.sock" {
+		t.Errorf("Expected /tmp/custom.sock, got %s", got)
+	}
+
+	t.Setenv("DOCKER_HOST", "tcp://build-host:2376")
+	if got := findDockerSocket(); got != "" {
+		t.Errorf("Expected remote DOCKER_HOST to be skipped, got %s", got)
+	}
+}
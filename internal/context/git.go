@@ -0,0 +1,98 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// maxGitChanges bounds how many changed paths are listed individually
+const maxGitChanges = 10
+
+// GitProvider describes the git repository containing the working directory
+type GitProvider struct{}
+
+// Name returns the provider name
+func (p *GitProvider) Name() string {
+	return "git"
+}
+
+// branchName returns the branch of a `## main...origin/main [ahead 1]` status line, without
+// its upstream state
+func branchName(line string) string {
+	branch := strings.TrimPrefix(line, "## ")
+	branch, _, _ = strings.Cut(branch, "...")
+	branch, _, _ = strings.Cut(branch, " [")
+	return branch
+}
+
+// Provide reports the branch, upstream state, pending changes and last commit
+func (p *GitProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	root, err := gitOutput(ctx, env.WorkingDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, nil // Not a repository
+	}
+
+	status, err := gitOutput(ctx, env.WorkingDir, "status", "--porcelain=v1", "--branch")
+	if err != nil {
+		return nil, err
+	}
+
+	section := &Section{Title: "GIT REPOSITORY"}
+	section.Items = append(section.Items, fmt.Sprintf("Root: %s", root))
+	section.Identity = append(section.Identity, root)
+
+	modified, untracked := 0, 0
+	var changes []string
+	for _, line := range strings.Split(status, "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			section.Items = append(section.Items, fmt.Sprintf("Branch: %s", strings.TrimPrefix(line, "## ")))
+			section.Identity = append(section.Identity, branchName(line))
+		case strings.HasPrefix(line, "?? "):
+			untracked++
+			changes = appendChange(changes, line, root, env.Ignore)
+		case len(line) > 3:
+			modified++
+			changes = appendChange(changes, line, root, env.Ignore)
+		}
+	}
+	section.Items = append(section.Items, fmt.Sprintf("Changes: %d modified/staged, %d untracked", modified, untracked))
+
+	if subject, err := gitOutput(ctx, env.WorkingDir, "log", "-1", "--format=%h %s"); err == nil && subject != "" {
+		section.Items = append(section.Items, fmt.Sprintf("Last commit: %s", subject))
+	}
+
+	section.Items = append(section.Items, changes...)
+
+	return section, nil
+}
+
+// appendChange adds a porcelain status line unless the path is excluded by .zchatignore
+func appendChange(changes []string, line, root string, ignore *IgnoreList) []string {
+	if len(changes) >= maxGitChanges {
+		return changes
+	}
+
+	path := line[3:]
+	if _, dest, ok := strings.Cut(path, " -> "); ok {
+		path = dest
+	}
+	if ignore != nil && ignore.Ignored(filepath.Join(root, strings.Trim(path, `"`)), strings.HasSuffix(path, "/")) {
+		return changes
+	}
+
+	return append(changes, line)
+}
+
+// gitOutput runs a git subcommand in dir and returns its trimmed output
+func gitOutput(ctx stdcontext.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
@@ -0,0 +1,80 @@
+package context
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.email=t@example.com", "-c", "user.name=t", "commit", "-q", "--allow-empty", "-m", "initial commit"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestGitProvider(t *testing.T) {
+	dir := initRepo(t)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "client.secret"), []byte("x"), 0644)
+
+	ignore := &IgnoreList{}
+	rule, _ := parseIgnorePattern("*.secret", dir)
+	ignore.rules = append(ignore.rules, rule)
+
+	section, err := (&GitProvider{}).Provide(context.Background(), &ProviderEnv{WorkingDir: dir, Ignore: ignore})
+	if err != nil || section == nil {
+		t.Fatalf("Provide() failed: %v", err)
+	}
+
+	joined := strings.Join(section.Items, "\n")
+	for _, expected := range []string{"Branch: main", "0 modified/staged, 2 untracked", "initial commit", "?? new.txt"} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("Expected %q in git section, got:\n%s", expected, joined)
+		}
+	}
+
+	if strings.Contains(joined, "client.secret") {
+		t.Error("Ignored paths should not be listed")
+	}
+	if len(section.Identity) != 2 || section.Identity[1] != "main" {
+		t.Errorf("Expected the root and branch as identity, got %q", section.Identity)
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := map[string]string{
+		"## main":               "main",
+		"## main...origin/main": "main",
+		"## feature-a...origin/feature-a [ahead 2]": "feature-a",
+		"## HEAD (no branch)":                       "HEAD (no branch)",
+	}
+
+	for line, expected := range tests {
+		if got := branchName(line); got != expected {
+			t.Errorf("branchName(%q): expected %q, got %q", line, expected, got)
+		}
+	}
+}
+
+func TestGitProvider_NotARepository(t *testing.T) {
+	section, err := (&GitProvider{}).Provide(context.Background(), &ProviderEnv{WorkingDir: t.TempDir()})
+
+	if err != nil || section != nil {
+		t.Errorf("Expected no section outside a repository, got %v (%v)", section, err)
+	}
+}
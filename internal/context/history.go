@@ -0,0 +1,248 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	stdcontext "context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/redact"
+)
+
+// zshMeta is the byte zsh uses to escape special characters in its history file
+const zshMeta = 0x83
+
+// HistoryReader reads recent commands from the user's shell history file
+type HistoryReader struct {
+	maxEntries int
+	maxChars   int
+	redactor   *redact.Redactor
+}
+
+// HistoryProvider exposes recent shell history as a context section
+type HistoryProvider struct {
+	reader *HistoryReader
+}
+
+// Name returns the provider name
+func (p *HistoryProvider) Name() string {
+	return "history"
+}
+
+// Provide returns recent history entries, oldest first
+func (p *HistoryProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	entries, err := p.reader.Read(env.Shell)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Section{
+		Title:    `RECENT SHELL HISTORY (oldest first, for resolving references like "that" or "the same"; do not re-run blindly)`,
+		Items:    entries,
+		KeepTail: true,
+	}, nil
+}
+
+// NewHistoryReader creates a history reader bounded by entry and character limits
+func NewHistoryReader(maxEntries, maxChars int) *HistoryReader {
+	return &HistoryReader{
+		maxEntries: maxEntries,
+		maxChars:   maxChars,
+		redactor:   redact.New(),
+	}
+}
+
+// Read returns the most recent history entries for the given shell, oldest first
+func (h *HistoryReader) Read(shell string) ([]string, error) {
+	path, err := historyPath(shell)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	switch filepath.Base(shell) {
+	case "fish":
+		entries = parseFishHistory(data)
+	case "zsh":
+		entries = parseZshHistory(data)
+	default:
+		entries = parseBashHistory(data)
+	}
+
+	return h.limit(entries), nil
+}
+
+// limit keeps the newest entries that fit in the entry and character budgets
+func (h *HistoryReader) limit(entries []string) []string {
+	var kept []string
+	chars := 0
+
+	for i := len(entries) - 1; i >= 0 && len(kept) < h.maxEntries; i-- {
+		entry := h.redactor.String(strings.TrimSpace(entries[i]))
+		if entry == "" {
+			continue
+		}
+		if chars+len(entry) > h.maxChars {
+			break
+		}
+		chars += len(entry)
+		kept = append(kept, entry)
+	}
+
+	// Restore chronological order
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+
+	return kept
+}
+
+// historyPath locates the history file for a shell, honoring $HISTFILE for bash and zsh
+func historyPath(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	switch filepath.Base(shell) {
+	case "fish":
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(dataHome, "fish", "fish_history"), nil
+	case "zsh":
+		if histFile := os.Getenv("HISTFILE"); histFile != "" {
+			return histFile, nil
+		}
+		return filepath.Join(home, ".zsh_history"), nil
+	default:
+		if histFile := os.Getenv("HISTFILE"); histFile != "" {
+			return histFile, nil
+		}
+		return filepath.Join(home, ".bash_history"), nil
+	}
+}
+
+// parseZshHistory parses both plain and extended (": <ts>:<dur>;cmd") zsh history
+func parseZshHistory(data []byte) []string {
+	data = unmetafy(data)
+
+	var entries []string
+	var current strings.Builder
+	continued := false
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !continued && strings.HasPrefix(line, ": ") {
+			if idx := strings.Index(line, ";"); idx != -1 {
+				line = line[idx+1:]
+			}
+		}
+
+		// A trailing backslash means the command continues on the next line
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString("\n")
+			continued = true
+			continue
+		}
+
+		current.WriteString(line)
+		entries = append(entries, current.String())
+		current.Reset()
+		continued = false
+	}
+
+	return entries
+}
+
+// unmetafy reverses zsh's escaping of bytes >= 0x83 in the history file
+func unmetafy(data []byte) []byte {
+	if bytes.IndexByte(data, zshMeta) == -1 {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == zshMeta && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^32)
+			continue
+		}
+		out = append(out, data[i])
+	}
+
+	return out
+}
+
+// parseBashHistory parses bash history, skipping "#<timestamp>" lines
+func parseBashHistory(data []byte) []string {
+	var entries []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isBashTimestamp(line) {
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	return entries
+}
+
+// isBashTimestamp reports whether a line is a HISTTIMEFORMAT timestamp marker
+func isBashTimestamp(line string) bool {
+	if len(line) < 2 || line[0] != '#' {
+		return false
+	}
+	for _, r := range line[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFishHistory extracts "- cmd: ..." entries from fish's YAML-like history
+func parseFishHistory(data []byte) []string {
+	var entries []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if cmd, ok := strings.CutPrefix(line, "- cmd: "); ok {
+			entries = append(entries, unescapeFish(cmd))
+		}
+	}
+
+	return entries
+}
+
+// unescapeFish reverses fish's escaping of newlines and backslashes
+func unescapeFish(cmd string) string {
+	var sb strings.Builder
+	for i := 0; i < len(cmd); i++ {
+		if cmd[i] == '\\' && i+1 < len(cmd) {
+			switch cmd[i+1] {
+			case 'n':
+				sb.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				sb.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(cmd[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,220 @@
+package context
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the per-directory file listing paths that must never be sent to the LLM
+const IgnoreFileName = ".zchatignore"
+
+// contextOffDirective disables context collection for the tree containing the ignore file
+const contextOffDirective = "context: off"
+
+type ignoreRule struct {
+	base    string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// IgnoreList holds gitignore-style rules gathered from the global and per-directory ignore files
+type IgnoreList struct {
+	rules      []ignoreRule
+	contextOff bool
+}
+
+// LoadIgnoreList reads the global ignore file and every .zchatignore from the filesystem root down to dir
+func LoadIgnoreList(dir string) (*IgnoreList, error) {
+	list := &IgnoreList{}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if err := list.loadFile(globalIgnorePath(home), string(filepath.Separator), home); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	// Collect ancestors first so that deeper files are loaded last and take precedence
+	var dirs []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := list.loadFile(filepath.Join(dirs[i], IgnoreFileName), dirs[i], ""); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// ContextOff reports whether a "context: off" directive applies
+func (l *IgnoreList) ContextOff() bool {
+	return l.contextOff
+}
+
+// Ignored reports whether path, or any directory containing it, is excluded
+func (l *IgnoreList) Ignored(path string, isDir bool) bool {
+	path = filepath.Clean(path)
+	if l.matches(path, isDir) {
+		return true
+	}
+
+	for d := filepath.Dir(path); ; d = filepath.Dir(d) {
+		if l.matches(d, true) {
+			return true
+		}
+		if filepath.Dir(d) == d {
+			return false
+		}
+	}
+}
+
+// matches applies the rules to a single path; the last matching rule wins
+func (l *IgnoreList) matches(path string, isDir bool) bool {
+	ignored := false
+
+	for _, rule := range l.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(rule.base, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rule.pattern.MatchString(filepath.ToSlash(rel)) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// loadFile parses an ignore file whose patterns are relative to base.
+// home is only set for the global file: there "~/" is expanded and "context: off" is
+// ignored, since the directive scopes a directory tree rather than the whole machine.
+func (l *IgnoreList) loadFile(path, base, home string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if line == contextOffDirective {
+			l.contextOff = l.contextOff || home == ""
+			continue
+		}
+
+		if home != "" && strings.HasPrefix(line, "~/") {
+			line = filepath.ToSlash(home) + line[1:]
+		}
+
+		if rule, ok := parseIgnorePattern(line, base); ok {
+			l.rules = append(l.rules, rule)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseIgnorePattern converts one gitignore-syntax line into a rule
+func parseIgnorePattern(line, base string) (ignoreRule, bool) {
+	rule := ignoreRule{base: base}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to the ignore file's directory
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule, false
+	}
+	rule.pattern = pattern
+
+	return rule, true
+}
+
+// globToRegexp translates gitignore wildcards ("*", "?", "**", "[...]") to a regular expression
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
+
+// globalIgnorePath returns the location of the user-wide ignore file, next to config.yaml
+func globalIgnorePath(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "zchat", "zchatignore")
+	}
+	return filepath.Join(home, ".config", "zchat", "zchatignore")
+}
@@ -0,0 +1,159 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnored_Patterns(t *testing.T) {
+	base := "/work"
+	list := &IgnoreList{}
+	for _, line := range []string{"*.pem", "/build", "secrets/", "docs/**/draft-*", "!keep.pem"} {
+		rule, ok := parseIgnorePattern(line, base)
+		if !ok {
+			t.Fatalf("Failed to parse pattern %q", line)
+		}
+		list.rules = append(list.rules, rule)
+	}
+
+	testCases := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"/work/server.pem", false, true},
+		{"/work/nested/dir/client.pem", false, true},
+		{"/work/keep.pem", false, false},
+		{"/work/build", true, true},
+		{"/work/src/build", true, false},
+		{"/work/secrets", true, true},
+		{"/work/secrets/token.txt", false, true},
+		{"/work/secrets", false, false},
+		{"/work/docs/a/b/draft-1.md", false, true},
+		{"/work/docs/draft-2.md", false, true},
+		{"/work/docs/final.md", false, false},
+		{"/elsewhere/server.pem", false, false},
+	}
+
+	for _, tc := range testCases {
+		if got := list.Ignored(tc.path, tc.isDir); got != tc.expected {
+			t.Errorf("Ignored(%s, dir=%v): expected %v, got %v", tc.path, tc.isDir, tc.expected, got)
+		}
+	}
+}
+
+func TestLoadIgnoreList_WalksUp(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	dir := filepath.Join(root, "clients", "acme")
+	os.MkdirAll(dir, 0755)
+
+	writeFile(t, root, IgnoreFileName, []byte("# client work\n*.key\n"))
+	writeFile(t, filepath.Join(root, "clients"), IgnoreFileName, []byte("contract-*.pdf\n"))
+
+	list, err := LoadIgnoreList(dir)
+	if err != nil {
+		t.Fatalf("LoadIgnoreList() failed: %v", err)
+	}
+
+	if !list.Ignored(filepath.Join(dir, "deploy.key"), false) {
+		t.Error("Expected pattern from an ancestor directory to apply")
+	}
+
+	if !list.Ignored(filepath.Join(dir, "contract-2024.pdf"), false) {
+		t.Error("Expected pattern from the parent directory to apply")
+	}
+
+	if list.Ignored(filepath.Join(dir, "notes.txt"), false) {
+		t.Error("Expected unmatched file to be kept")
+	}
+
+	if list.ContextOff() {
+		t.Error("Expected context collection to stay enabled")
+	}
+}
+
+func TestLoadIgnoreList_Global(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	os.MkdirAll(filepath.Join(home, ".config", "zchat"), 0755)
+	writeFile(t, filepath.Join(home, ".config", "zchat"), "zchatignore", []byte("~/Documents/legal\n*.kdbx\ncontext: off\n"))
+
+	legal := filepath.Join(home, "Documents", "legal")
+	os.MkdirAll(legal, 0755)
+
+	list, err := LoadIgnoreList(legal)
+	if err != nil {
+		t.Fatalf("LoadIgnoreList() failed: %v", err)
+	}
+
+	if !list.Ignored(filepath.Join(legal, "nda.docx"), false) {
+		t.Error("Expected files under a globally ignored directory to be excluded")
+	}
+
+	if !list.Ignored("/tmp/passwords.kdbx", false) {
+		t.Error("Expected unanchored global pattern to match anywhere")
+	}
+
+	if list.ContextOff() {
+		t.Error("A global 'context: off' should not disable collection everywhere")
+	}
+}
+
+func TestCollect_ContextOff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, dir, IgnoreFileName, []byte("context: off\n"))
+	writeFile(t, dir, "client-list.txt", []byte("acme"))
+
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+	writeFile(t, sub, "notes.txt", []byte("private"))
+	t.Chdir(sub)
+
+	ctx, err := NewDefaultCollector(20).Collect()
+	if err != nil {
+		t.Fatalf("Collect() failed: %v", err)
+	}
+
+	if !ctx.ContextOff {
+		t.Error("Expected ContextOff for a directory under 'context: off'")
+	}
+
+	if ctx.WorkingDir != "" || len(ctx.Files) != 0 {
+		t.Errorf("Expected no directory context, got %s %v", ctx.WorkingDir, ctx.Files)
+	}
+
+	if samples := NewFileSampler(4096).Sample("cat ../client-list.txt", sub); len(samples) != 0 {
+		t.Errorf("Expected no samples under 'context: off', got %v", samples)
+	}
+
+	// The withheld working directory must not fall back to the process's own
+	if samples := NewFileSampler(4096).Sample("count words in notes.txt", ctx.WorkingDir); len(samples) != 0 {
+		t.Errorf("Expected no samples for the withheld directory, got %v", samples)
+	}
+}
+
+func TestCollect_ExcludesIgnoredFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, dir, IgnoreFileName, []byte("*.secret\n"))
+	writeFile(t, dir, "visible.txt", []byte("ok"))
+	writeFile(t, dir, "hidden.secret", []byte("no"))
+	t.Chdir(dir)
+
+	ctx, err := NewDefaultCollector(20).Collect()
+	if err != nil {
+		t.Fatalf("Collect() failed: %v", err)
+	}
+
+	if len(ctx.Files) != 1 || ctx.Files[0] != "visible.txt" {
+		t.Errorf("Expected only visible.txt, got %v", ctx.Files)
+	}
+
+	if samples := NewFileSampler(4096).Sample("cat hidden.secret", dir); len(samples) != 0 {
+		t.Errorf("Expected ignored file not to be sampled, got %v", samples)
+	}
+}
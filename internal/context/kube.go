@@ -0,0 +1,88 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KubeProvider reports the current Kubernetes context and namespace from kubeconfig.
+// Only names are sent; server URLs and credentials are never read into the section.
+type KubeProvider struct {
+	configPath string
+}
+
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// NewKubeProvider creates a provider for the given kubeconfig, or $KUBECONFIG / ~/.kube/config if empty
+func NewKubeProvider(configPath string) *KubeProvider {
+	if configPath == "" {
+		configPath = findKubeConfig()
+	}
+	return &KubeProvider{configPath: configPath}
+}
+
+// Name returns the provider name
+func (p *KubeProvider) Name() string {
+	return "kube"
+}
+
+// Provide reports the current context, its cluster and namespace
+func (p *KubeProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	data, err := os.ReadFile(p.configPath)
+	if err != nil {
+		return nil, nil // No kubeconfig
+	}
+
+	var cfg kubeConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	if cfg.CurrentContext == "" {
+		return nil, nil
+	}
+
+	section := &Section{Title: "KUBERNETES (kubectl targets this context unless told otherwise)"}
+	section.Items = append(section.Items, fmt.Sprintf("Current context: %s", cfg.CurrentContext))
+
+	for _, c := range cfg.Contexts {
+		if c.Name != cfg.CurrentContext {
+			continue
+		}
+		namespace := c.Context.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		section.Items = append(section.Items, fmt.Sprintf("Cluster: %s", c.Context.Cluster))
+		section.Items = append(section.Items, fmt.Sprintf("Namespace: %s", namespace))
+	}
+	section.Identity = slices.Clone(section.Items) // nothing here drifts
+
+	return section, nil
+}
+
+// findKubeConfig returns the first path in $KUBECONFIG, or ~/.kube/config
+func findKubeConfig() string {
+	if paths := os.Getenv("KUBECONFIG"); paths != "" {
+		return strings.Split(paths, string(os.PathListSeparator))[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
@@ -0,0 +1,77 @@
+package context
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: staging-cluster
+  cluster:
+    server: https://10.0.0.1:6443
+contexts:
+- name: staging
+  context:
+    cluster: staging-cluster
+    user: deploy
+    namespace: payments
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: deploy
+users:
+- name: deploy
+  user:
+    token: super-secret-token
+`
+
+func TestKubeProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config", []byte(testKubeConfig))
+
+	section, err := NewKubeProvider(filepath.Join(dir, "config")).Provide(context.Background(), &ProviderEnv{})
+	if err != nil || section == nil {
+		t.Fatalf("Expected section, got %v, %v", section, err)
+	}
+
+	joined := strings.Join(section.Items, "\n")
+	for _, want := range []string{"Current context: staging", "Cluster: staging-cluster", "Namespace: payments"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected %q in %q", want, joined)
+		}
+	}
+	for _, leaked := range []string{"10.0.0.1", "super-secret-token", "prod"} {
+		if strings.Contains(joined, leaked) {
+			t.Errorf("Expected %q not to be included, got %q", leaked, joined)
+		}
+	}
+}
+
+func TestKubeProvider_DefaultNamespace(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config", []byte(strings.Replace(testKubeConfig, "current-context: staging", "current-context: prod", 1)))
+
+	section, _ := NewKubeProvider(filepath.Join(dir, "config")).Provide(context.Background(), &ProviderEnv{})
+	if section == nil || !strings.Contains(strings.Join(section.Items, "\n"), "Namespace: default") {
+		t.Errorf("Expected default namespace, got %v", section)
+	}
+}
+
+func TestKubeProvider_Missing(t *testing.T) {
+	section, err := NewKubeProvider(filepath.Join(t.TempDir(), "none")).Provide(context.Background(), &ProviderEnv{})
+	if section != nil || err != nil {
+		t.Errorf("Expected nothing without a kubeconfig, got %v, %v", section, err)
+	}
+}
+
+func TestFindKubeConfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/tmp/a.yaml:/tmp/b.yaml")
+	if got := findKubeConfig(); got != "/tmp/a.yaml" {
+		t.Errorf("Expected first KUBECONFIG entry, got %s", got)
+	}
+}
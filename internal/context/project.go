@@ -0,0 +1,151 @@
+package context
+
+import (
+	"bufio"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const maxProjectTargets = 15
+
+var (
+	goModulePattern   = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+	tomlNamePattern   = regexp.MustCompile(`(?m)^name\s*=\s*"([^"]+)"`)
+	makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.-]*)\s*:([^=]|$)`)
+)
+
+// ProjectProvider recognizes the kind of project in the working directory from its marker files
+type ProjectProvider struct{}
+
+// Name returns the provider name
+func (p *ProjectProvider) Name() string {
+	return "project"
+}
+
+// Provide describes build systems, package managers and task runners found in the working directory
+func (p *ProjectProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	section := &Section{Title: "PROJECT"}
+	dir := env.WorkingDir
+
+	exists := func(name string) bool {
+		path := filepath.Join(dir, name)
+		if env.Ignore != nil && env.Ignore.Ignored(path, false) {
+			return false
+		}
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	read := func(name string) string {
+		if !exists(name) {
+			return ""
+		}
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		return string(data)
+	}
+
+	if data := read("go.mod"); data != "" {
+		item := "Go module"
+		if m := goModulePattern.FindStringSubmatch(data); m != nil {
+			item += " " + m[1]
+		}
+		section.Items = append(section.Items, item)
+	}
+
+	if data := read("package.json"); data != "" {
+		section.Items = append(section.Items, describePackageJSON(data, exists))
+	}
+
+	if data := read("Cargo.toml"); data != "" {
+		item := "Rust crate (cargo)"
+		if m := tomlNamePattern.FindStringSubmatch(data); m != nil {
+			item += " " + m[1]
+		}
+		section.Items = append(section.Items, item)
+	}
+
+	for _, marker := range []string{"pyproject.toml", "requirements.txt", "setup.py", "Pipfile"} {
+		if exists(marker) {
+			item := "Python project (" + marker + ")"
+			for _, venv := range []string{".venv", "venv"} {
+				if exists(venv) {
+					item += ", virtualenv in " + venv
+					break
+				}
+			}
+			section.Items = append(section.Items, item)
+			break
+		}
+	}
+
+	for _, marker := range []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"} {
+		if exists(marker) {
+			section.Items = append(section.Items, "Docker Compose file: "+marker)
+			break
+		}
+	}
+	if exists("Dockerfile") {
+		section.Items = append(section.Items, "Dockerfile present")
+	}
+
+	if data := read("Makefile"); data != "" {
+		if targets := makeTargets(data); len(targets) > 0 {
+			section.Items = append(section.Items, "Make targets: "+strings.Join(targets, ", "))
+		}
+	}
+	if exists("justfile") || exists("Justfile") {
+		section.Items = append(section.Items, "justfile present (use `just`)")
+	}
+
+	return section, nil
+}
+
+// describePackageJSON summarizes a package.json: name, package manager and script names
+func describePackageJSON(data string, exists func(string) bool) string {
+	var pkg struct {
+		Name    string            `json:"name"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	json.Unmarshal([]byte(data), &pkg)
+
+	manager := "npm"
+	switch {
+	case exists("pnpm-lock.yaml"):
+		manager = "pnpm"
+	case exists("yarn.lock"):
+		manager = "yarn"
+	case exists("bun.lockb"):
+		manager = "bun"
+	}
+
+	item := fmt.Sprintf("Node.js package %s (%s)", pkg.Name, manager)
+	if len(pkg.Scripts) > 0 {
+		var scripts []string
+		for name := range pkg.Scripts {
+			scripts = append(scripts, name)
+		}
+		sort.Strings(scripts)
+		item += ", scripts: " + strings.Join(scripts, ", ")
+	}
+
+	return item
+}
+
+// makeTargets extracts explicit target names from a Makefile
+func makeTargets(data string) []string {
+	var targets []string
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() && len(targets) < maxProjectTargets {
+		if m := makeTargetPattern.FindStringSubmatch(scanner.Text()); m != nil {
+			targets = append(targets, m[1])
+		}
+	}
+
+	return targets
+}
@@ -0,0 +1,45 @@
+package context
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProjectProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", []byte("module github.com/acme/api\n\ngo 1.22\n"))
+	writeFile(t, dir, "package.json", []byte(`{"name": "web", "scripts": {"test": "jest", "build": "vite build"}}`))
+	writeFile(t, dir, "pnpm-lock.yaml", []byte(""))
+	writeFile(t, dir, "Makefile", []byte(".PHONY: test\nVAR := 1\ntest: build\n\tgo test ./...\nbuild:\n\tgo build\n"))
+	writeFile(t, dir, "compose.yaml", []byte("services: {}\n"))
+
+	section, err := (&ProjectProvider{}).Provide(context.Background(), &ProviderEnv{WorkingDir: dir})
+	if err != nil {
+		t.Fatalf("Provide() failed: %v", err)
+	}
+
+	joined := strings.Join(section.Items, "\n")
+	expected := []string{
+		"Go module github.com/acme/api",
+		"Node.js package web (pnpm), scripts: build, test",
+		"Make targets: test, build",
+		"Docker Compose file: compose.yaml",
+	}
+	for _, e := range expected {
+		if !strings.Contains(joined, e) {
+			t.Errorf("Expected %q in project section, got:\n%s", e, joined)
+		}
+	}
+}
+
+func TestProjectProvider_Empty(t *testing.T) {
+	section, err := (&ProjectProvider{}).Provide(context.Background(), &ProviderEnv{WorkingDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Provide() failed: %v", err)
+	}
+
+	if len(section.Items) != 0 {
+		t.Errorf("Expected no project items for an empty directory, got %v", section.Items)
+	}
+}
@@ -0,0 +1,188 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProviderTimeout bounds each provider unless its limit says otherwise
+	DefaultProviderTimeout = 2 * time.Second
+	// DefaultContextBudget is the total number of characters shared by all provider sections
+	DefaultContextBudget = 6000
+)
+
+// Section is a titled block of context produced by one provider
+type Section struct {
+	Provider string   // name of the provider that produced it, e.g. "git"
+	Title    string   // heading shown to the model
+	Items    []string // one entry per line, most important first
+	// KeepTail trims items from the start instead of the end when over budget (e.g. history)
+	KeepTail bool
+	// Identity, if set, is what the section is about without the state that drifts between
+	// runs, like a branch without its change counts; the response cache keys on it instead of Items
+	Identity []string
+}
+
+// ProviderEnv is what providers know about the environment they describe
+type ProviderEnv struct {
+	WorkingDir string
+	Shell      string
+	Ignore     *IgnoreList
+}
+
+// ContextProvider is a single source of context such as files, git or history
+type ContextProvider interface {
+	Name() string
+	// Provide returns the provider's section, or nil if it has nothing to contribute
+	Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error)
+}
+
+// ProviderLimit bounds a single provider's run time and share of the context budget
+type ProviderLimit struct {
+	Timeout  time.Duration
+	MaxChars int
+}
+
+type registeredProvider struct {
+	provider ContextProvider
+	limit    ProviderLimit
+}
+
+// Size returns the number of characters the section's items occupy
+func (s *Section) Size() int {
+	size := 0
+	for _, item := range s.Items {
+		size += len(item)
+	}
+	return size
+}
+
+// Trim drops items until the section fits in maxChars
+func (s *Section) Trim(maxChars int) {
+	for len(s.Items) > 0 && s.Size() > maxChars {
+		if s.KeepTail {
+			s.Items = s.Items[1:]
+		} else {
+			s.Items = s.Items[:len(s.Items)-1]
+		}
+	}
+}
+
+// runProviders runs every provider concurrently, each under its own timeout, and returns
+// their sections in registration order. Failing or slow providers are skipped.
+func runProviders(providers []registeredProvider, env *ProviderEnv) []Section {
+	results := make([]*Section, len(providers))
+
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runProvider(p, env)
+		}()
+	}
+	wg.Wait()
+
+	var sections []Section
+	for _, section := range results {
+		if section != nil && len(section.Items) > 0 {
+			sections = append(sections, *section)
+		}
+	}
+
+	return sections
+}
+
+// runProvider runs a single provider, abandoning it if it outlives its timeout
+func runProvider(p registeredProvider, env *ProviderEnv) *Section {
+	timeout := p.limit.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProviderTimeout
+	}
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), timeout)
+	defer cancel()
+
+	type result struct {
+		section *Section
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		section, err := p.provider.Provide(ctx, env)
+		done <- result{section, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil || r.section == nil {
+			return nil
+		}
+		r.section.Provider = p.provider.Name()
+		if p.limit.MaxChars > 0 {
+			r.section.Trim(p.limit.MaxChars)
+		}
+		return r.section
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// allocateBudget shares budget characters across sections. Small sections keep everything
+// and the remainder is split evenly among the larger ones.
+func allocateBudget(sections []Section, budget int) {
+	if budget <= 0 {
+		return
+	}
+
+	order := make([]int, len(sections))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sections[order[a]].Size() < sections[order[b]].Size()
+	})
+
+	remaining := budget
+	for n, i := range order {
+		share := remaining / (len(order) - n)
+		sections[i].Trim(share)
+		remaining -= sections[i].Size()
+	}
+}
+
+// NewProvider creates a built-in provider by name
+func NewProvider(name string, opts ProviderOptions) (ContextProvider, error) {
+	switch name {
+	case "files":
+		return &FilesProvider{maxFiles: opts.MaxFiles}, nil
+	case "history":
+		return &HistoryProvider{reader: NewHistoryReader(opts.HistoryEntries, opts.HistoryMaxChars)}, nil
+	case "aliases":
+		return &AliasProvider{reader: NewAliasReader(DefaultCacheDir()), available: opts.AliasesAvailable}, nil
+	case "git":
+		return &GitProvider{}, nil
+	case "tools":
+		return &ToolsProvider{}, nil
+	case "project":
+		return &ProjectProvider{}, nil
+	case "docker":
+		return NewDockerProvider(""), nil
+	case "kube":
+		return NewKubeProvider(""), nil
+	default:
+		return nil, fmt.Errorf("unknown context provider: %s", name)
+	}
+}
+
+// ProviderOptions carries provider-specific settings from the configuration
+type ProviderOptions struct {
+	MaxFiles         int
+	HistoryEntries   int
+	HistoryMaxChars  int
+	AliasesAvailable bool
+}
@@ -0,0 +1,149 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeProvider struct {
+	name  string
+	items []string
+	delay time.Duration
+	err   error
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Provide(ctx context.Context, env *ProviderEnv) (*Section, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Section{Title: strings.ToUpper(p.name), Items: p.items}, nil
+}
+
+func TestRunProviders_OrderAndFailures(t *testing.T) {
+	providers := []registeredProvider{
+		{provider: &fakeProvider{name: "slow-first", items: []string{"a"}, delay: 50 * time.Millisecond}},
+		{provider: &fakeProvider{name: "broken", err: errors.New("boom")}},
+		{provider: &fakeProvider{name: "fast-second", items: []string{"b"}}},
+		{provider: &fakeProvider{name: "empty"}},
+	}
+
+	sections := runProviders(providers, &ProviderEnv{})
+
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d: %v", len(sections), sections)
+	}
+
+	if sections[0].Provider != "slow-first" || sections[1].Provider != "fast-second" {
+		t.Errorf("Expected sections in registration order, got %s, %s", sections[0].Provider, sections[1].Provider)
+	}
+}
+
+func TestRunProviders_Concurrent(t *testing.T) {
+	var providers []registeredProvider
+	for i := 0; i < 5; i++ {
+		providers = append(providers, registeredProvider{
+			provider: &fakeProvider{name: "p", items: []string{"x"}, delay: 100 * time.Millisecond},
+		})
+	}
+
+	start := time.Now()
+	runProviders(providers, &ProviderEnv{})
+
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("Expected providers to run concurrently, took %v", elapsed)
+	}
+}
+
+func TestRunProviders_Timeout(t *testing.T) {
+	providers := []registeredProvider{
+		{
+			provider: &fakeProvider{name: "hung", items: []string{"late"}, delay: time.Hour},
+			limit:    ProviderLimit{Timeout: 20 * time.Millisecond},
+		},
+	}
+
+	start := time.Now()
+	sections := runProviders(providers, &ProviderEnv{})
+
+	if len(sections) != 0 {
+		t.Errorf("Expected timed-out provider to be dropped, got %v", sections)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected timeout to be enforced, took %v", elapsed)
+	}
+}
+
+func TestRunProviders_MaxChars(t *testing.T) {
+	providers := []registeredProvider{
+		{
+			provider: &fakeProvider{name: "big", items: []string{"aaaa", "bbbb", "cccc"}},
+			limit:    ProviderLimit{MaxChars: 9},
+		},
+	}
+
+	sections := runProviders(providers, &ProviderEnv{})
+
+	if len(sections) != 1 || strings.Join(sections[0].Items, ",") != "aaaa,bbbb" {
+		t.Errorf("Expected per-provider limit to keep 2 items, got %v", sections)
+	}
+}
+
+func TestAllocateBudget(t *testing.T) {
+	sections := []Section{
+		{Provider: "small", Items: []string{"12345"}},
+		{Provider: "large", Items: []string{"1234567890", "1234567890", "1234567890", "1234567890"}},
+		{Provider: "history", Items: []string{"old-entry1", "old-entry2", "new-entry3", "new-entry4"}, KeepTail: true},
+	}
+
+	allocateBudget(sections, 45)
+
+	total := 0
+	for _, s := range sections {
+		total += s.Size()
+	}
+	if total > 45 {
+		t.Errorf("Expected total size within budget, got %d", total)
+	}
+
+	if sections[0].Size() != 5 {
+		t.Errorf("Expected small section to be kept whole, got %v", sections[0].Items)
+	}
+
+	if len(sections[1].Items) != 2 {
+		t.Errorf("Expected large section to get half of the remainder, got %v", sections[1].Items)
+	}
+
+	if strings.Join(sections[2].Items, ",") != "new-entry3,new-entry4" {
+		t.Errorf("Expected history to keep its newest entries, got %v", sections[2].Items)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	for _, name := range []string{"files", "history", "aliases", "git", "tools", "project"} {
+		provider, err := NewProvider(name, ProviderOptions{MaxFiles: 10})
+		if err != nil {
+			t.Errorf("NewProvider(%s) failed: %v", name, err)
+			continue
+		}
+		if provider.Name() != name {
+			t.Errorf("Expected provider name '%s', got '%s'", name, provider.Name())
+		}
+	}
+
+	if _, err := NewProvider("nonexistent", ProviderOptions{}); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
@@ -0,0 +1,39 @@
+package context
+
+import (
+	"github.com/palaforcade/zchat/internal/redact"
+)
+
+// Redact masks secrets in every field that is sent to the LLM and returns what was masked
+func (s *SystemContext) Redact(r *redact.Redactor) []redact.Finding {
+	var findings []redact.Finding
+
+	mask := func(value string) string {
+		cleaned, found := r.Redact(value)
+		findings = append(findings, found...)
+		return cleaned
+	}
+
+	s.WorkingDir = mask(s.WorkingDir)
+	for i, file := range s.Files {
+		s.Files[i] = mask(file)
+	}
+	for i := range s.Sections {
+		items := s.Sections[i].Items
+		for j, item := range items {
+			items[j] = mask(item)
+		}
+	}
+	for i := range s.Samples {
+		sample := &s.Samples[i]
+		sample.Name = mask(sample.Name)
+		for j, key := range sample.Keys {
+			sample.Keys[j] = mask(key)
+		}
+		for j, line := range sample.Lines {
+			sample.Lines[j] = mask(line)
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,327 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSampledFiles  = 3
+	maxPreviewLines  = 5
+	maxPreviewLine   = 200
+	maxPreviewKeys   = 30
+	binarySniffBytes = 512
+)
+
+// FileSample is a bounded preview of a file mentioned in the user's query
+type FileSample struct {
+	Name      string
+	Size      int64
+	Kind      string // "csv", "tsv", "json", "text" or "binary"
+	Delimiter string
+	Keys      []string
+	Lines     []string
+	FileType  string // detected from magic bytes for binaries
+}
+
+// FileSampler attaches previews of files named in a query
+type FileSampler struct {
+	maxBytes int
+}
+
+// NewFileSampler creates a sampler that reads at most maxBytes from each file
+func NewFileSampler(maxBytes int) *FileSampler {
+	return &FileSampler{
+		maxBytes: maxBytes,
+	}
+}
+
+// Sample returns previews for files in workingDir that the query mentions by name.
+// Files excluded by .zchatignore are never read, and nothing is when workingDir is
+// empty, as it is when the context is withheld.
+func (s *FileSampler) Sample(query, workingDir string) []FileSample {
+	if workingDir == "" {
+		return nil
+	}
+	ignore, err := LoadIgnoreList(workingDir)
+	if err != nil || ignore.ContextOff() {
+		return nil
+	}
+
+	var samples []FileSample
+	seen := make(map[string]bool)
+
+	for _, name := range mentionedFiles(query) {
+		path := filepath.Join(workingDir, name)
+		if seen[path] || !withinDir(workingDir, path) || ignore.Ignored(path, false) {
+			continue
+		}
+		seen[path] = true
+
+		sample, err := s.sampleFile(path, name)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, *sample)
+		if len(samples) >= maxSampledFiles {
+			break
+		}
+	}
+
+	return samples
+}
+
+// sampleFile builds a preview of a single regular file
+func (s *FileSampler) sampleFile(path, name string) (*FileSample, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, os.ErrInvalid
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head, err := io.ReadAll(io.LimitReader(f, int64(s.maxBytes)))
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &FileSample{Name: name, Size: info.Size()}
+	truncated := info.Size() > int64(len(head))
+
+	if isBinary(head) {
+		sample.Kind = "binary"
+		sample.FileType = detectFileType(head)
+		return sample, nil
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".csv", ".tsv":
+		sample.Kind = strings.TrimPrefix(ext, ".")
+		sample.Lines = previewLines(head, truncated)
+		sample.Delimiter = detectDelimiter(sample.Lines, ext)
+	case ".json":
+		sample.Kind = "json"
+		sample.Keys = jsonKeys(head)
+	default:
+		sample.Kind = "text"
+		sample.Lines = previewLines(head, truncated)
+	}
+
+	return sample, nil
+}
+
+// mentionedFiles extracts tokens from the query that look like file names
+func mentionedFiles(query string) []string {
+	var names []string
+
+	for _, field := range strings.Fields(query) {
+		name := strings.Trim(field, "\"'`()[]{}<>,;:!?")
+		name = strings.TrimRight(name, ".")
+		if name == "" || (!strings.Contains(name, ".") && !strings.Contains(name, "/")) {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// withinDir reports whether path is inside dir, so queries can't pull in "../" or absolute
+// paths, nor a symlink pointing out of dir
+func withinDir(dir, path string) bool {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// previewLines returns the first lines of text, truncating long lines and dropping a line cut off by the byte limit
+func previewLines(head []byte, truncated bool) []string {
+	text := strings.TrimSuffix(string(head), "\n")
+	if truncated {
+		if idx := strings.LastIndexByte(text, '\n'); idx != -1 {
+			text = text[:idx]
+		} else {
+			text = text[:runeBoundary(text, len(text))]
+		}
+	}
+	if text == "" {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(line) > maxPreviewLine {
+			line = line[:runeBoundary(line, maxPreviewLine)] + "…"
+		}
+		lines = append(lines, line)
+		if len(lines) >= maxPreviewLines {
+			break
+		}
+	}
+
+	return lines
+}
+
+// runeBoundary returns the largest n <= max at which s can be cut without splitting a
+// multi-byte character. When s is no longer than max, it drops a character cut off at the end.
+func runeBoundary(s string, max int) int {
+	if max < len(s) {
+		for max > 0 && !utf8.RuneStart(s[max]) {
+			max--
+		}
+		return max
+	}
+
+	i := len(s) - 1
+	for i > 0 && len(s)-i < utf8.UTFMax && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	if i >= 0 && !utf8.FullRuneInString(s[i:]) {
+		return i
+	}
+	return len(s)
+}
+
+// detectDelimiter picks the separator that splits every preview line into the same number of fields
+func detectDelimiter(lines []string, ext string) string {
+	if ext == ".tsv" {
+		return "\t"
+	}
+	if len(lines) == 0 {
+		return ","
+	}
+
+	best, bestCount := ",", 0
+	for _, delim := range []string{",", "\t", ";", "|"} {
+		count := strings.Count(lines[0], delim)
+		if count == 0 {
+			continue
+		}
+		consistent := true
+		for _, line := range lines[1:] {
+			if strings.Count(line, delim) != count {
+				consistent = false
+				break
+			}
+		}
+		if consistent && count > bestCount {
+			best, bestCount = delim, count
+		}
+	}
+
+	return best
+}
+
+// jsonKeys lists the top-level keys of a JSON object, or of the first element of a JSON array.
+// It stops quietly at the byte limit, so large documents yield the keys seen so far.
+func jsonKeys(head []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(head))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil
+	}
+	if tok == json.Delim('[') {
+		if tok, err = dec.Token(); err != nil {
+			return nil
+		}
+	}
+	if tok != json.Delim('{') {
+		return nil
+	}
+
+	var keys []string
+	for dec.More() && len(keys) < maxPreviewKeys {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, ok := tok.(string)
+		if !ok {
+			break
+		}
+		keys = append(keys, key)
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			break
+		}
+	}
+
+	return keys
+}
+
+// isBinary reports whether the data looks like a non-text file
+func isBinary(head []byte) bool {
+	sniff := head[:min(len(head), binarySniffBytes)]
+	if bytes.IndexByte(sniff, 0) != -1 {
+		return true
+	}
+
+	// Ignore a multi-byte character cut off at the end of the sniffed window
+	for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(sniff); i++ {
+		if r, _ := utf8.DecodeLastRune(sniff); r != utf8.RuneError {
+			break
+		}
+		sniff = sniff[:len(sniff)-1]
+	}
+	return !utf8.Valid(sniff)
+}
+
+// magicNumbers maps well-known file signatures to a human-readable type
+var magicNumbers = []struct {
+	offset    int
+	signature []byte
+	fileType  string
+}{
+	{0, []byte("\x89PNG\r\n\x1a\n"), "PNG image"},
+	{0, []byte("\xff\xd8\xff"), "JPEG image"},
+	{0, []byte("GIF8"), "GIF image"},
+	{0, []byte("%PDF-"), "PDF document"},
+	{0, []byte("PK\x03\x04"), "ZIP archive (or docx/xlsx/jar)"},
+	{0, []byte("\x1f\x8b"), "gzip compressed data"},
+	{0, []byte("BZh"), "bzip2 compressed data"},
+	{0, []byte("\xfd7zXZ\x00"), "xz compressed data"},
+	{0, []byte("7z\xbc\xaf\x27\x1c"), "7-zip archive"},
+	{0, []byte("\x28\xb5\x2f\xfd"), "zstd compressed data"},
+	{0, []byte("\x7fELF"), "ELF executable"},
+	{0, []byte("\xcf\xfa\xed\xfe"), "Mach-O executable"},
+	{0, []byte("\xca\xfe\xba\xbe"), "Mach-O universal binary"},
+	{0, []byte("SQLite format 3\x00"), "SQLite database"},
+	{0, []byte("PAR1"), "Parquet file"},
+	{0, []byte("\x00asm"), "WebAssembly module"},
+	{257, []byte("ustar"), "tar archive"},
+}
+
+// detectFileType identifies a binary file from its leading bytes
+func detectFileType(head []byte) string {
+	for _, m := range magicNumbers {
+		end := m.offset + len(m.signature)
+		if len(head) >= end && bytes.Equal(head[m.offset:end], m.signature) {
+			return m.fileType
+		}
+	}
+
+	return http.DetectContentType(head)
+}
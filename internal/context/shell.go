@@ -0,0 +1,122 @@
+package context
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxProcDepth bounds how many ancestors are inspected when looking for the invoking shell
+const maxProcDepth = 6
+
+// knownShells maps process names to canonical shell names
+var knownShells = map[string]string{
+	"sh":           "sh",
+	"dash":         "sh",
+	"ash":          "sh",
+	"bash":         "bash",
+	"zsh":          "zsh",
+	"ksh":          "ksh",
+	"mksh":         "ksh",
+	"fish":         "fish",
+	"pwsh":         "pwsh",
+	"pwsh-preview": "pwsh",
+	"powershell":   "pwsh",
+}
+
+// fallbackShells are tried in order when neither the parent process nor $SHELL yields a usable shell
+var fallbackShells = []string{"/bin/zsh", "/bin/bash", "/bin/sh"}
+
+// ShellName returns the canonical name ("bash", "zsh", "fish", "pwsh", ...) for a shell path
+func ShellName(shell string) string {
+	base := strings.TrimPrefix(filepath.Base(shell), "-") // login shells are often "-zsh"
+	base = strings.TrimSuffix(base, ".exe")
+	if name, ok := knownShells[base]; ok {
+		return name
+	}
+	return base
+}
+
+// DetectShell finds the shell that invoked zchat by walking parent processes,
+// falling back to $SHELL and then to the first shell that exists on the system
+func DetectShell() string {
+	if shell := detectShellFromProc("/proc", os.Getppid()); shell != "" {
+		return shell
+	}
+
+	return ResolveShell("")
+}
+
+// ResolveShell returns shell if its binary exists, otherwise $SHELL or the first existing fallback
+func ResolveShell(shell string) string {
+	candidates := append([]string{shell, os.Getenv("SHELL")}, fallbackShells...)
+	for _, candidate := range candidates {
+		if candidate != "" && shellExists(candidate) {
+			return candidate
+		}
+	}
+
+	return "/bin/sh"
+}
+
+// detectShellFromProc walks /proc from pid upwards and returns the first known shell's executable
+func detectShellFromProc(procRoot string, pid int) string {
+	for depth := 0; depth < maxProcDepth && pid > 1; depth++ {
+		dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+		comm, ppid, err := readProcStat(dir)
+		if err != nil {
+			return ""
+		}
+
+		if _, ok := knownShells[strings.TrimPrefix(comm, "-")]; ok {
+			if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil && shellExists(exe) {
+				return exe
+			}
+			// exe is unreadable for other users' processes; look the name up instead
+			if path, err := exec.LookPath(strings.TrimPrefix(comm, "-")); err == nil {
+				return path
+			}
+		}
+
+		pid = ppid
+	}
+
+	return ""
+}
+
+// readProcStat returns the command name and parent pid from /proc/<pid>/stat
+func readProcStat(dir string) (string, int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Format is "pid (comm) state ppid ..."; comm may itself contain spaces or parentheses
+	stat := string(data)
+	open := strings.IndexByte(stat, '(')
+	end := strings.LastIndexByte(stat, ')')
+	if open == -1 || end < open {
+		return "", 0, os.ErrInvalid
+	}
+
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return "", 0, os.ErrInvalid
+	}
+
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, err
+	}
+
+	return stat[open+1 : end], ppid, nil
+}
+
+// shellExists reports whether a shell path points to an executable file
+func shellExists(shell string) bool {
+	info, err := os.Stat(shell)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
@@ -0,0 +1,103 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// fakeProc creates a /proc-like tree with one process per entry, each the parent of the next
+func fakeProc(t *testing.T, procs []struct {
+	pid, ppid int
+	comm, exe string
+}) string {
+	t.Helper()
+	root := t.TempDir()
+
+	for _, p := range procs {
+		dir := filepath.Join(root, strconv.Itoa(p.pid))
+		os.Mkdir(dir, 0755)
+		stat := strconv.Itoa(p.pid) + " (" + p.comm + ") S " + strconv.Itoa(p.ppid) + " 1 1 0"
+		os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644)
+		if p.exe != "" {
+			os.Symlink(p.exe, filepath.Join(dir, "exe"))
+		}
+	}
+
+	return root
+}
+
+func TestDetectShellFromProc(t *testing.T) {
+	root := fakeProc(t, []struct {
+		pid, ppid int
+		comm, exe string
+	}{
+		{300, 200, "go", "/usr/local/bin/go"},
+		{200, 100, "bash", "/bin/bash"},
+		{100, 1, "zsh", "/bin/zsh"},
+	})
+
+	shell := detectShellFromProc(root, 300)
+
+	if shell != "/bin/bash" {
+		t.Errorf("Expected nearest ancestor shell '/bin/bash', got '%s'", shell)
+	}
+}
+
+func TestDetectShellFromProc_NoShell(t *testing.T) {
+	root := fakeProc(t, []struct {
+		pid, ppid int
+		comm, exe string
+	}{
+		{200, 100, "sshd", "/usr/sbin/sshd"},
+		{100, 1, "systemd", "/lib/systemd/systemd"},
+	})
+
+	if shell := detectShellFromProc(root, 200); shell != "" {
+		t.Errorf("Expected no shell, got '%s'", shell)
+	}
+}
+
+func TestReadProcStat_CommWithParens(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "stat"), []byte("42 (my (weird) proc) S 7 42 42 0"), 0644)
+
+	comm, ppid, err := readProcStat(dir)
+	if err != nil {
+		t.Fatalf("readProcStat() failed: %v", err)
+	}
+
+	if comm != "my (weird) proc" || ppid != 7 {
+		t.Errorf("Expected 'my (weird) proc' / 7, got '%s' / %d", comm, ppid)
+	}
+}
+
+func TestResolveShell(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+
+	if shell := ResolveShell("/bin/sh"); shell != "/bin/sh" {
+		t.Errorf("Expected existing shell to be kept, got '%s'", shell)
+	}
+
+	if shell := ResolveShell("/nonexistent/zsh"); shell != "/bin/sh" {
+		t.Errorf("Expected fallback to $SHELL, got '%s'", shell)
+	}
+}
+
+func TestShellName(t *testing.T) {
+	testCases := map[string]string{
+		"/bin/zsh":                         "zsh",
+		"-zsh":                             "zsh",
+		"/usr/bin/dash":                    "sh",
+		"/usr/bin/fish":                    "fish",
+		"/opt/microsoft/powershell/7/pwsh": "pwsh",
+		"/usr/local/bin/nu":                "nu",
+	}
+
+	for shell, expected := range testCases {
+		if name := ShellName(shell); name != expected {
+			t.Errorf("ShellName(%s): expected '%s', got '%s'", shell, expected, name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// toolCategories lists the commands worth telling the model about, grouped by purpose
+var toolCategories = []struct {
+	name  string
+	tools []string
+}{
+	{"search", []string{"rg", "ag", "fd", "fzf"}},
+	{"files", []string{"eza", "exa", "bat", "tree", "trash"}},
+	{"data", []string{"jq", "yq", "xsv", "mlr", "csvkit", "sqlite3", "duckdb"}},
+	{"network", []string{"curl", "wget", "httpie", "http"}},
+	{"vcs", []string{"git", "gh", "glab"}},
+	{"containers", []string{"docker", "podman", "kubectl", "helm", "k9s"}},
+	{"languages", []string{"python3", "node", "go", "cargo", "ruby", "java"}},
+	{"packages", []string{"brew", "apt", "dnf", "pacman", "npm", "pnpm", "yarn", "pip3", "uv"}},
+	{"build", []string{"make", "just", "cmake"}},
+	{"gnu", []string{"gsed", "gawk", "gfind", "gdate"}},
+}
+
+// ToolsProvider reports which notable command-line tools are installed
+type ToolsProvider struct{}
+
+// Name returns the provider name
+func (p *ToolsProvider) Name() string {
+	return "tools"
+}
+
+// Provide lists installed tools by category
+func (p *ToolsProvider) Provide(ctx stdcontext.Context, env *ProviderEnv) (*Section, error) {
+	section := &Section{Title: "INSTALLED TOOLS (prefer these over assuming others exist)"}
+
+	for _, category := range toolCategories {
+		var found []string
+		for _, tool := range category.tools {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if _, err := exec.LookPath(tool); err == nil {
+				found = append(found, tool)
+			}
+		}
+		if len(found) > 0 {
+			section.Items = append(section.Items, fmt.Sprintf("%s: %s", category.name, strings.Join(found, ", ")))
+		}
+	}
+	section.Identity = slices.Clone(section.Items) // a probe that times out drops the whole section
+
+	return section, nil
+}
@@ -0,0 +1,56 @@
+package context
+
+import (
+	stdcontext "context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToolsProvider(t *testing.T) {
+	bin := t.TempDir()
+	for _, tool := range []string{"rg", "fd", "jq", "make"} {
+		if err := os.WriteFile(filepath.Join(bin, tool), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("Failed to write %s: %v", tool, err)
+		}
+	}
+	os.WriteFile(filepath.Join(bin, "yq"), []byte("not executable"), 0644)
+	t.Setenv("PATH", bin)
+
+	section, err := (&ToolsProvider{}).Provide(stdcontext.Background(), &ProviderEnv{})
+	if err != nil {
+		t.Fatalf("Provide() failed: %v", err)
+	}
+
+	if got := strings.Join(section.Items, "; "); got != "search: rg, fd; data: jq; build: make" {
+		t.Errorf("Expected installed tools by category, got %q", got)
+	}
+}
+
+func TestToolsProvider_FindsGo(t *testing.T) {
+	section, err := (&ToolsProvider{}).Provide(stdcontext.Background(), &ProviderEnv{})
+	if err != nil {
+		t.Fatalf("Provide() failed: %v", err)
+	}
+
+	// The test suite itself needs go, so at least the languages category should be present
+	found := false
+	for _, item := range section.Items {
+		if strings.HasPrefix(item, "languages:") && strings.Contains(item, "go") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected go to be reported as installed, got %v", section.Items)
+	}
+}
+
+func TestToolsProvider_Cancelled(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	cancel()
+
+	if _, err := (&ToolsProvider{}).Provide(ctx, &ProviderEnv{}); err == nil {
+		t.Error("Expected a cancelled probe to fail")
+	}
+}
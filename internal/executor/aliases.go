@@ -0,0 +1,69 @@
+package executor
+
+import "strings"
+
+// maxAliasDepth bounds alias expansion, as a guard against aliases that refer to each other
+const maxAliasDepth = 10
+
+// commandWords are reserved words after which a new command starts, as after a separator
+var commandWords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "do": true, "while": true, "until": true,
+	"time": true, "!": true,
+}
+
+// ExpandAliases returns command as the shell runs it with the user's rc files loaded: the
+// first word of each simple command is replaced by its alias or function body, repeatedly, as
+// the shell does. Safety checks look at the result, so an alias like ll='rm -rf' can't hide
+// a dangerous command. Words in quotes are left alone.
+func ExpandAliases(command string, aliases map[string]string) string {
+	return expandAliases(command, aliases, map[string]bool{}, 0)
+}
+
+// expandAliases expands command, skipping the aliases being expanded, which the shell doesn't
+// expand again inside their own definition
+func expandAliases(command string, aliases map[string]string, expanding map[string]bool, depth int) string {
+	var out strings.Builder
+	start := true
+
+	for i := 0; i < len(command); {
+		c := command[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(command[i+1:], c)
+			if end < 0 {
+				out.WriteString(command[i:])
+				return out.String()
+			}
+			out.WriteString(command[i : i+end+2])
+			i += end + 2
+			start = false
+		case strings.IndexByte("|;&\n(`{", c) >= 0:
+			out.WriteByte(c)
+			i++
+			start = true
+		case strings.IndexByte(" \t)}", c) >= 0:
+			out.WriteByte(c)
+			i++
+		default:
+			end := i
+			for end < len(command) && strings.IndexByte(" \t\n|;&(){}`'\"", command[end]) < 0 {
+				end++
+			}
+			word := command[i:end]
+			i = end
+
+			definition, ok := aliases[word]
+			if start && ok && !expanding[word] && depth < maxAliasDepth {
+				expanding[word] = true
+				out.WriteString(expandAliases(definition, aliases, expanding, depth+1))
+				delete(expanding, word)
+			} else {
+				out.WriteString(word)
+			}
+			// Assignments and reserved words come before the command name
+			start = start && (strings.Contains(word, "=") || commandWords[word])
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,51 @@
+package executor
+
+import "testing"
+
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string]string{
+		"ll":    "ls -la",
+		"nuke":  "rm -rf",
+		"clean": "nuke build",
+		"ls":    "ls --color=auto",
+		"loop":  "loop2",
+		"loop2": "loop",
+		"tidy":  `find . -name '*.tmp' -delete; }`,
+	}
+
+	testCases := []struct {
+		command  string
+		expected string
+	}{
+		{"ll /tmp", "ls --color=auto -la /tmp"},
+		{"nuke ~", "rm -rf ~"},
+		{"clean", "rm -rf build"},
+		{"cd src && nuke *", "cd src && rm -rf *"},
+		{"cat x | nuke /", "cat x | rm -rf /"},
+		{"FOO=1 nuke /", "FOO=1 rm -rf /"},
+		{"echo $(nuke /)", "echo $(rm -rf /)"},
+		{"if true; then nuke /; fi", "if true; then rm -rf /; fi"},
+		{"echo nuke", "echo nuke"},
+		{"echo 'a; nuke /'", "echo 'a; nuke /'"},
+		{"loop", "loop"},
+		{"tidy", `find . -name '*.tmp' -delete; }`},
+		{"git status", "git status"},
+	}
+
+	for _, tc := range testCases {
+		if got := ExpandAliases(tc.command, aliases); got != tc.expected {
+			t.Errorf("ExpandAliases(%q) = %q, expected %q", tc.command, got, tc.expected)
+		}
+	}
+}
+
+func TestExpandAliases_SafetyChecks(t *testing.T) {
+	aliases := map[string]string{"ll": "rm -rf", "ls": "rm"}
+
+	if isDangerous, _ := IsDangerous(ExpandAliases("ll /", aliases), []string{"rm -rf /"}); !isDangerous {
+		t.Error("Expected an alias hiding rm -rf / to be dangerous")
+	}
+	if IsReadOnly(ExpandAliases("ls notes.txt", aliases)) {
+		t.Error("Expected an alias shadowing ls with rm not to be read-only")
+	}
+}
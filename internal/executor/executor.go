@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"sync"
+
+	sysContext "github.com/palaforcade/zchat/internal/context"
 )
 
 type Executor interface {
@@ -15,13 +19,13 @@ type Executor interface {
 type SafeExecutor struct {
 	dangerousPatterns []string
 	shell             string
+	withAliases       bool
 }
 
-// NewSafeExecutor creates a new executor with safety patterns
+// NewSafeExecutor creates a new executor with safety patterns.
+// If the shell is empty or its binary doesn't exist, an installed shell is used instead.
 func NewSafeExecutor(patterns []string, shell string) *SafeExecutor {
-	if shell == "" {
-		shell = "/bin/zsh"
-	}
+	shell = sysContext.ResolveShell(shell)
 
 	return &SafeExecutor{
 		dangerousPatterns: patterns,
@@ -29,27 +33,89 @@ func NewSafeExecutor(patterns []string, shell string) *SafeExecutor {
 	}
 }
 
+// EnableAliases makes commands run with the user's rc files sourced, so aliases and functions expand
+func (e *SafeExecutor) EnableAliases() {
+	e.withAliases = true
+}
+
+// Output is what a command wrote, both interleaved as the user would see it and per stream
+type Output struct {
+	Combined string
+	Stdout   string
+	Stderr   string
+}
+
 // Execute executes a shell command safely
 func (e *SafeExecutor) Execute(ctx context.Context, command string) (string, error) {
+	output, err := e.Run(ctx, command)
+	return output.Combined, err
+}
+
+// Run executes a shell command like Execute, keeping stdout and stderr apart as well
+func (e *SafeExecutor) Run(ctx context.Context, command string) (*Output, error) {
 	// Safety check (should never get here as UI checks first, but double-checking)
 	if isDangerous, reason := IsDangerous(command, e.dangerousPatterns); isDangerous {
-		return "", fmt.Errorf("refused to execute dangerous command: %s", reason)
+		return &Output{}, fmt.Errorf("refused to execute dangerous command: %s", reason)
 	}
 
 	// Execute command using shell
-	cmd := exec.CommandContext(ctx, e.shell, "-c", command)
+	args := shellArgs(e.shell, command)
+	if e.withAliases {
+		args = aliasShellArgs(e.shell, command)
+	}
+	cmd := exec.CommandContext(ctx, e.shell, args...)
 
-	// Capture output
-	var output bytes.Buffer
-	cmd.Stdout = &output
-	cmd.Stderr = &output
+	// Capture output; the streams are copied concurrently, so the shared buffer is locked
+	var combined, stdout, stderr bytes.Buffer
+	var mu sync.Mutex
+	cmd.Stdout = io.MultiWriter(&stdout, &lockedWriter{mu: &mu, w: &combined})
+	cmd.Stderr = io.MultiWriter(&stderr, &lockedWriter{mu: &mu, w: &combined})
 	cmd.Env = os.Environ()
 
 	// Run command
 	err := cmd.Run()
+	output := &Output{Combined: combined.String(), Stdout: stdout.String(), Stderr: stderr.String()}
 	if err != nil {
-		return output.String(), fmt.Errorf("command execution failed: %w", err)
+		return output, fmt.Errorf("command execution failed: %w", err)
 	}
 
-	return output.String(), nil
+	return output, nil
+}
+
+// lockedWriter serializes writes from several goroutines into one writer
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// shellArgs returns the arguments that make a shell run a single command non-interactively
+func shellArgs(shell, command string) []string {
+	switch sysContext.ShellName(shell) {
+	case "pwsh":
+		return []string{"-NoProfile", "-NonInteractive", "-Command", command}
+	default:
+		return []string{"-c", command}
+	}
+}
+
+// aliasShellArgs returns arguments that load the user's rc files before running the command.
+// The command is eval'd after sourcing, because aliases are expanded when a line is parsed.
+func aliasShellArgs(shell, command string) []string {
+	switch sysContext.ShellName(shell) {
+	case "zsh":
+		return []string{"-c", `source "${ZDOTDIR:-$HOME}/.zshrc" >/dev/null 2>&1 </dev/null; eval "$1"`, "zchat", command}
+	case "bash":
+		// .bashrc usually returns early unless the shell is interactive
+		return []string{"-i", "-c", `eval "$1"`, "zchat", command}
+	case "fish":
+		return []string{"-i", "-c", command}
+	default:
+		return shellArgs(shell, command)
+	}
 }
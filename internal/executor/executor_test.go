@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -25,8 +26,35 @@ func TestNewSafeExecutor(t *testing.T) {
 func TestNewSafeExecutor_DefaultShell(t *testing.T) {
 	exec := NewSafeExecutor([]string{}, "")
 
-	if exec.shell != "/bin/zsh" {
-		t.Errorf("Expected default shell '/bin/zsh', got '%s'", exec.shell)
+	if _, err := os.Stat(exec.shell); err != nil {
+		t.Errorf("Expected default shell to exist, got '%s': %v", exec.shell, err)
+	}
+}
+
+func TestNewSafeExecutor_MissingShell(t *testing.T) {
+	exec := NewSafeExecutor([]string{}, "/nonexistent/zsh")
+
+	if exec.shell == "/nonexistent/zsh" {
+		t.Error("Expected a missing shell binary to be replaced by an installed one")
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	testCases := []struct {
+		shell    string
+		expected string
+	}{
+		{"/bin/bash", "-c|ls"},
+		{"/usr/bin/zsh", "-c|ls"},
+		{"/usr/bin/fish", "-c|ls"},
+		{"/usr/bin/pwsh", "-NoProfile|-NonInteractive|-Command|ls"},
+	}
+
+	for _, tc := range testCases {
+		args := strings.Join(shellArgs(tc.shell, "ls"), "|")
+		if args != tc.expected {
+			t.Errorf("For %s: expected '%s', got '%s'", tc.shell, tc.expected, args)
+		}
 	}
 }
 
@@ -129,3 +157,45 @@ echo "line2"`
 		t.Errorf("Expected both lines in output, got '%s'", output)
 	}
 }
+
+func TestExecute_WithAliases(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.WriteFile(home+"/.bashrc", []byte("alias greet='echo hello from alias'\n"), 0644)
+
+	exec := NewSafeExecutor([]string{}, "/bin/bash")
+	if exec.shell != "/bin/bash" {
+		t.Skip("bash not installed")
+	}
+	exec.EnableAliases()
+
+	output, err := exec.Execute(context.Background(), "greet")
+	if err != nil {
+		t.Fatalf("Execute() failed: %v (output: %s)", err, output)
+	}
+
+	if !strings.Contains(output, "hello from alias") {
+		t.Errorf("Expected alias to expand, got '%s'", output)
+	}
+}
+
+func TestRun_SeparatesStreams(t *testing.T) {
+	exec := NewSafeExecutor([]string{}, "/bin/sh")
+
+	output, err := exec.Run(context.Background(), "echo out; echo err >&2; echo more")
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if output.Stdout != "out\nmore\n" {
+		t.Errorf("Expected stdout 'out\\nmore\\n', got '%s'", output.Stdout)
+	}
+	if output.Stderr != "err\n" {
+		t.Errorf("Expected stderr 'err\\n', got '%s'", output.Stderr)
+	}
+	for _, want := range []string{"out", "err", "more"} {
+		if !strings.Contains(output.Combined, want) {
+			t.Errorf("Expected combined output to contain '%s', got '%s'", want, output.Combined)
+		}
+	}
+}
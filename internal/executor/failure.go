@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	failureFile     = "last_failure.json"
+	maxTailLines    = 20
+	maxTailChars    = 2000
+	unknownExitCode = -1
+)
+
+// Failure records a command that exited with an error so it can be corrected later
+type Failure struct {
+	Query    string `json:"query"`
+	Command  string `json:"command"`
+	ExitCode int    `json:"exit_code"`
+	Output   string `json:"output"` // tail of the combined stdout and stderr
+}
+
+// NewFailure builds a failure record from the result of Execute
+func NewFailure(query, command, output string, err error) *Failure {
+	return &Failure{
+		Query:    query,
+		Command:  command,
+		ExitCode: ExitCode(err),
+		Output:   OutputTail(output),
+	}
+}
+
+// ExitCode returns the exit status carried by an Execute error, or -1 if the command
+// didn't exit normally (e.g. it was killed by the timeout)
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return unknownExitCode
+}
+
+// OutputTail keeps the last lines of command output, where errors usually are
+func OutputTail(output string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > maxTailLines {
+		lines = lines[len(lines)-maxTailLines:]
+	}
+
+	tail := strings.Join(lines, "\n")
+	if len(tail) > maxTailChars {
+		tail = tail[len(tail)-maxTailChars:]
+	}
+	return tail
+}
+
+// SaveFailure stores the failure in dir, replacing any previous one
+func SaveFailure(dir string, f *Failure) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, failureFile), data, 0600)
+}
+
+// LoadFailure returns the last stored failure, or nil if there is none
+func LoadFailure(dir string) (*Failure, error) {
+	data, err := os.ReadFile(filepath.Join(dir, failureFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f Failure
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ClearFailure forgets the last failure once a command has succeeded
+func ClearFailure(dir string) {
+	os.Remove(filepath.Join(dir, failureFile))
+}
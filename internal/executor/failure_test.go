@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewFailure_ExitCode(t *testing.T) {
+	exec := NewSafeExecutor([]string{}, "/bin/sh")
+
+	output, err := exec.Execute(context.Background(), "echo boom >&2; exit 3")
+	if err == nil {
+		t.Fatal("Expected command to fail")
+	}
+
+	f := NewFailure("do it", "echo boom >&2; exit 3", output, err)
+	if f.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", f.ExitCode)
+	}
+	if f.Output != "boom" {
+		t.Errorf("Expected output tail 'boom', got %q", f.Output)
+	}
+}
+
+func TestExitCode_NotExitError(t *testing.T) {
+	if code := ExitCode(nil); code != 0 {
+		t.Errorf("Expected 0 for nil error, got %d", code)
+	}
+	if code := ExitCode(context.DeadlineExceeded); code != -1 {
+		t.Errorf("Expected -1 for non-exit error, got %d", code)
+	}
+}
+
+func TestOutputTail(t *testing.T) {
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, strings.Repeat("x", 10))
+	}
+	lines = append(lines, "last line")
+
+	tail := OutputTail(strings.Join(lines, "\n") + "\n")
+	if !strings.HasSuffix(tail, "last line") {
+		t.Errorf("Expected tail to end with the last line, got %q", tail)
+	}
+	if n := strings.Count(tail, "\n") + 1; n != maxTailLines {
+		t.Errorf("Expected %d lines, got %d", maxTailLines, n)
+	}
+
+	long := OutputTail(strings.Repeat("y", 5000))
+	if len(long) != maxTailChars {
+		t.Errorf("Expected %d chars, got %d", maxTailChars, len(long))
+	}
+}
+
+func TestSaveLoadFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	if f, err := LoadFailure(dir); f != nil || err != nil {
+		t.Fatalf("Expected no failure initially, got %v, %v", f, err)
+	}
+
+	saved := &Failure{Query: "count lines", Command: "wc -l nope.txt", ExitCode: 1, Output: "wc: nope.txt: No such file"}
+	if err := SaveFailure(dir, saved); err != nil {
+		t.Fatalf("SaveFailure failed: %v", err)
+	}
+
+	loaded, err := LoadFailure(dir)
+	if err != nil || loaded == nil {
+		t.Fatalf("Expected saved failure, got %v, %v", loaded, err)
+	}
+	if *loaded != *saved {
+		t.Errorf("Expected %+v, got %+v", saved, loaded)
+	}
+
+	ClearFailure(dir)
+	if f, _ := LoadFailure(dir); f != nil {
+		t.Errorf("Expected failure to be cleared, got %+v", f)
+	}
+}
@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// readOnlyPrograms only read files or report state, whatever their arguments
+var readOnlyPrograms = map[string]bool{
+	"ls": true, "cat": true, "head": true, "tail": true, "less": true, "more": true,
+	"grep": true, "egrep": true, "fgrep": true, "rg": true, "ag": true,
+	"wc": true, "sort": true, "cut": true, "tr": true, "column": true, "nl": true, "jq": true,
+	"diff": true, "cmp": true, "comm": true, "md5sum": true, "sha1sum": true, "sha256sum": true,
+	"stat": true, "file": true, "du": true, "df": true, "tree": true, "realpath": true, "readlink": true,
+	"basename": true, "dirname": true, "pwd": true, "echo": true, "printf": true, "true": true,
+	"date": true, "cal": true, "whoami": true, "id": true, "groups": true, "uname": true, "hostname": true,
+	"uptime": true, "free": true, "ps": true, "pgrep": true, "lsof": true, "ss": true, "netstat": true,
+	"which": true, "type": true, "printenv": true, "locale": true, "nproc": true,
+}
+
+// readOnlySubcommands are the read-only subcommands of tools that can also change things
+var readOnlySubcommands = map[string][]string{
+	"git":     {"status", "log", "diff", "show", "blame", "ls-files", "ls-tree", "rev-parse", "describe", "shortlog", "grep", "cat-file"},
+	"docker":  {"ps", "images", "logs", "inspect", "version", "info", "stats"},
+	"kubectl": {"get", "describe", "logs", "version", "explain", "top"},
+	"go":      {"version", "list", "doc"},
+	"npm":     {"ls", "list", "view", "outdated"},
+}
+
+// findWriteActions make find delete files or run other programs
+var findWriteActions = []string{"-delete", "-exec", "-execdir", "-ok", "-okdir", "-fprint", "-fprint0", "-fprintf", "-fls"}
+
+// unsafeFlags make an otherwise read-only program write files, run other programs or change
+// state. Short flags match anywhere in a group like -ro, long ones also when abbreviated.
+var unsafeFlags = map[string][]string{
+	"rg":       {"--pre"},                                                               // runs a program on each file
+	"git":      {"--output", "-O", "--open-files-in-pager", "--ext-diff", "--textconv"}, // write a file or run other programs
+	"tree":     {"-o", "-R"},                                                            // write the listing to files
+	"less":     {"-o", "-O", "--log-file", "--LOG-FILE"},                                // copy the input to a file
+	"sort":     {"-o", "--output", "--compress-program"},                                // write a file or run a compressor
+	"date":     {"-s", "--set"},                                                         // set the clock
+	"hostname": {"-F", "--file"},                                                        // set the name from a file
+	"ss":       {"-K", "--kill"},                                                        // close sockets
+}
+
+// IsReadOnly reports whether command only reads: every stage of every pipeline or list must
+// be a known non-mutating program, with no output redirection to files and no command
+// substitution. Anything it doesn't recognize counts as mutating.
+func IsReadOnly(command string) bool {
+	segments, ok := splitCommand(command)
+	if !ok || len(segments) == 0 {
+		return false
+	}
+
+	for _, words := range segments {
+		if !isReadOnlySegment(words) {
+			return false
+		}
+	}
+	return true
+}
+
+// isReadOnlySegment checks one simple command, given as its words
+func isReadOnlySegment(words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+
+	program := filepath.Base(words[0])
+	args := words[1:]
+
+	if slices.ContainsFunc(args, func(arg string) bool { return isUnsafeFlag(arg, unsafeFlags[program]) }) {
+		return false
+	}
+
+	switch program {
+	case "find":
+		return !slices.ContainsFunc(args, func(arg string) bool { return slices.Contains(findWriteActions, arg) })
+	case "hostname":
+		// With an argument, hostname renames the machine
+		return !slices.ContainsFunc(args, func(arg string) bool { return !strings.HasPrefix(arg, "-") })
+	case "git":
+		// git branch lists branches only without arguments besides these flags
+		if len(args) > 0 && args[0] == "branch" {
+			return !slices.ContainsFunc(args[1:], func(arg string) bool {
+				return !slices.Contains([]string{"-a", "-r", "-v", "-vv", "--list", "--all", "--remotes", "--show-current"}, arg)
+			})
+		}
+		if len(args) > 1 && args[0] == "remote" {
+			return len(args) == 2 && (args[1] == "-v" || args[1] == "show")
+		}
+	}
+
+	if subcommands, ok := readOnlySubcommands[program]; ok {
+		return len(args) > 0 && slices.Contains(subcommands, args[0])
+	}
+	return readOnlyPrograms[program]
+}
+
+// isUnsafeFlag reports whether arg gives one of flags
+func isUnsafeFlag(arg string, flags []string) bool {
+	name, _, _ := strings.Cut(arg, "=")
+	for _, flag := range flags {
+		switch {
+		case strings.HasPrefix(flag, "--"):
+			if name == flag || len(name) > 3 && strings.HasPrefix(flag, name) {
+				return true
+			}
+		case !strings.HasPrefix(arg, "--") && strings.HasPrefix(arg, "-"):
+			if strings.Contains(arg[1:], flag[1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitCommand breaks command into simple commands at pipes, lists and separators, honoring
+// quotes. It fails on anything that could run or write beyond the listed programs: command or
+// process substitution, backgrounding, subshells, and redirection other than to /dev/null.
+func splitCommand(command string) ([][]string, bool) {
+	var segments [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endSegment := func() bool {
+		endWord()
+		if len(words) == 0 {
+			return false
+		}
+		segments = append(segments, words)
+		words = nil
+		return true
+	}
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, false
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '"':
+			end := strings.IndexByte(command[i+1:], '"')
+			if end < 0 {
+				return nil, false
+			}
+			quoted := command[i+1 : i+1+end]
+			if strings.Contains(quoted, "$(") || strings.Contains(quoted, "`") {
+				return nil, false
+			}
+			word.WriteString(quoted)
+			inWord = true
+			i += end + 1
+		case c == '\\' && i+1 < len(command):
+			word.WriteByte(command[i+1])
+			inWord = true
+			i++
+		case c == '`' || c == '(' || c == ')' || c == '{' && !inWord || c == '<' && i+1 < len(command) && command[i+1] == '(':
+			return nil, false
+		case c == '$' && i+1 < len(command) && command[i+1] == '(':
+			return nil, false
+		case c == '|' || c == ';' || c == '\n':
+			if !endSegment() {
+				return nil, false
+			}
+			if i+1 < len(command) && command[i+1] == '|' {
+				i++
+			}
+		case c == '&':
+			// Only && is allowed; a lone & would background the command
+			if i+1 >= len(command) || command[i+1] != '&' {
+				return nil, false
+			}
+			if !endSegment() {
+				return nil, false
+			}
+			i++
+		case c == '>':
+			// Allow discarding output (>/dev/null, 2>/dev/null) and merging streams (2>&1)
+			if inWord && word.String() != "2" && word.String() != "1" {
+				return nil, false
+			}
+			word.Reset()
+			inWord = false
+			rest := strings.TrimLeft(command[i+1:], " ")
+			switch {
+			case strings.HasPrefix(rest, "&1"), strings.HasPrefix(rest, "&2"):
+				i = len(command) - len(rest) + 1
+			case strings.HasPrefix(rest, "/dev/null") && endsWord(rest[len("/dev/null"):]):
+				i = len(command) - len(rest) + len("/dev/null") - 1
+			default:
+				return nil, false
+			}
+		case c == ' ' || c == '\t':
+			endWord()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if len(words) > 0 || inWord {
+		endSegment()
+	} else if len(segments) > 0 {
+		// A trailing operator like "ls |" is incomplete
+		return nil, false
+	}
+	return segments, true
+}
+
+// endsWord reports whether rest, the text after a word, starts where that word ends
+func endsWord(rest string) bool {
+	return rest == "" || strings.ContainsRune(" \t\n|;&", rune(rest[0]))
+}
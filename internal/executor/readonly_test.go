@@ -0,0 +1,86 @@
+package executor
+
+import "testing"
+
+func TestIsReadOnly(t *testing.T) {
+	testCases := []struct {
+		command  string
+		expected bool
+	}{
+		{"ls -la", true},
+		{"cat README.md | grep -i install | wc -l", true},
+		{"find . -name '*.go' -mtime -7", true},
+		{"git status", true},
+		{"git log --oneline -n 5 && git diff --stat", true},
+		{"git branch -a", true},
+		{"du -sh * 2>/dev/null | sort -rh | head", true},
+		{"grep -r 'a > b' src 2>&1", true},
+		{"/bin/ls /tmp; pwd", true},
+		{"kubectl get pods -n default", true},
+		{`echo "hello world"`, true},
+		{"wc -l < data.csv", true},
+		{"ls >/dev/null; pwd", true},
+		{"ls 2>/dev/null|wc -l", true},
+		{"git diff --no-ext-diff", true},
+		{"rg --pre-glob '*.gz' foo", true},
+		{"hostname -f", true},
+		{"ss -tlnp", true},
+
+		{"rm -rf build", false},
+		{"find . -name '*.tmp' -delete", false},
+		{`find . -type f -exec rm {} \;`, false},
+		{"git commit -m 'wip'", false},
+		{"git branch new-feature", false},
+		{"git push", false},
+		{"git diff --output=patch.diff", false},
+		{"ls > files.txt", false},
+		{"cat a >> b", false},
+		{"echo a>b", false},
+		{"sort -o out.txt in.txt", false},
+		{"cat $(ls)", false},
+		{"cat `ls`", false},
+		{`echo "$(rm -rf ~)"`, false},
+		{"ls & rm x", false},
+		{"ls | sh", false},
+		{"ls | xargs rm", false},
+		{"(cd /tmp && ls)", false},
+		{"FOO=1 ls", false},
+		{"date -s '2020-01-01'", false},
+		{"hostname newname", false},
+		{"docker rm web", false},
+		{"ls |", false},
+		{"echo 'unterminated", false},
+		{"", false},
+		{"sed -i s/a/b/ file", false},
+		{"awk '{print $1}' file", false},
+		{"rg --pre rm foo .", false},
+		{"rg --pre=rm foo .", false},
+		{"git reflog expire --expire=now --all", false},
+		{"git reflog delete HEAD@{1}", false},
+		{"git grep -Orm foo", false},
+		{"git grep --open-files-in-pager=rm foo", false},
+		{"git grep --open foo", false},
+		{"git diff --ext-diff", false},
+		{"git log -p --textconv", false},
+		{"tree -o out.txt", false},
+		{"tree -ao out.txt", false},
+		{"less -oout.txt file", false},
+		{"less -O out.txt file", false},
+		{"less --log-file=out.txt file", false},
+		{"sort --compress-program=sh in.txt", false},
+		{"sort --compress-prog=sh in.txt", false},
+		{"sort -ro out.txt in.txt", false},
+		{"hostname -F /tmp/name", false},
+		{"hostname --file /tmp/name", false},
+		{"ss -K dst 10.0.0.1", false},
+		{"ss -tK", false},
+		{"ls >/dev/nullx", false},
+		{"ls 2>/dev/null.log", false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsReadOnly(tc.command); got != tc.expected {
+			t.Errorf("IsReadOnly(%q) = %v, expected %v", tc.command, got, tc.expected)
+		}
+	}
+}
@@ -19,3 +19,28 @@ func IsDangerous(command string, patterns []string) (bool, string) {
 
 	return false, ""
 }
+
+// Risk levels reported by Assess
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// Risk is a verdict on a command with the reasons behind it
+type Risk struct {
+	Level   string   `json:"level"`
+	Reasons []string `json:"reasons"`
+}
+
+// Assess rates command: high if it matches a dangerous pattern, low if it only reads, and
+// medium otherwise, since it may change files or state
+func Assess(command string, patterns []string) Risk {
+	if isDangerous, reason := IsDangerous(command, patterns); isDangerous {
+		return Risk{Level: RiskHigh, Reasons: []string{reason}}
+	}
+	if IsReadOnly(command) {
+		return Risk{Level: RiskLow, Reasons: []string{"Command only reads files or state"}}
+	}
+	return Risk{Level: RiskMedium, Reasons: []string{"Command may change files or state"}}
+}
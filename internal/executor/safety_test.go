@@ -144,3 +144,26 @@ func TestIsDangerous_FalsePositiveCheck(t *testing.T) {
 		}
 	}
 }
+
+func TestAssess(t *testing.T) {
+	patterns := []string{"rm -rf /"}
+
+	testCases := []struct {
+		command string
+		level   string
+	}{
+		{"rm -rf /", RiskHigh},
+		{"ls -la | grep go", RiskLow},
+		{"touch file", RiskMedium},
+	}
+
+	for _, tc := range testCases {
+		risk := Assess(tc.command, patterns)
+		if risk.Level != tc.level {
+			t.Errorf("Assess(%q) level = %s, expected %s", tc.command, risk.Level, tc.level)
+		}
+		if len(risk.Reasons) == 0 {
+			t.Errorf("Assess(%q) gave no reasons", tc.command)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// FileName is the JSONL file holding one entry per line
+	FileName = "history.jsonl"
+	// maxEntries bounds the store; older entries are dropped when it grows past this
+	maxEntries = 5000
+)
+
+// Entry is one recorded interaction: what was asked, what was generated, and what happened
+type Entry struct {
+	ID         int       `json:"id"`
+	Time       time.Time `json:"time"`
+	Query      string    `json:"query"`   // as sent to the model, after redaction
+	Context    string    `json:"context"` // short summary of the directory and shell
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	Command    string    `json:"command"`
+	Edits      []string  `json:"edits,omitempty"` // earlier versions this command replaced, oldest first
+	Executed   bool      `json:"executed"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"`
+	RerunOf    int       `json:"rerun_of,omitempty"`
+}
+
+// Store keeps entries in a JSONL file
+type Store struct {
+	path string
+}
+
+// NewStore creates a store in dir
+func NewStore(dir string) *Store {
+	return &Store{
+		path: filepath.Join(dir, FileName),
+	}
+}
+
+// Add assigns the next ID to entry and appends it
+func (s *Store) Add(entry *Entry) error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	entry.ID = 1
+	if len(entries) > 0 {
+		entry.ID = entries[len(entries)-1].ID + 1
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	if len(entries) >= maxEntries {
+		return s.rewrite(append(entries[len(entries)-maxEntries+1:], *entry))
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// List returns up to limit of the most recent entries, oldest first
+func (s *Store) List(limit int) ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return tail(entries, limit), nil
+}
+
+// Search returns up to limit of the most recent entries whose query or command contains text
+func (s *Store) Search(text string, limit int) ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	text = strings.ToLower(text)
+	var matches []Entry
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Query), text) || strings.Contains(strings.ToLower(e.Command), text) {
+			matches = append(matches, e)
+		}
+	}
+	return tail(matches, limit), nil
+}
+
+// Get returns the entry with the given ID
+func (s *Store) Get(id int) (*Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no history entry with id %d", id)
+}
+
+// load reads every entry, skipping lines that don't parse (e.g. a write cut short)
+func (s *Store) load() ([]Entry, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// rewrite replaces the file with entries, via a temporary file so a crash can't truncate history
+func (s *Store) rewrite(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// tail returns the last limit entries, or all of them if limit is not positive
+func tail(entries []Entry, limit int) []Entry {
+	if limit > 0 && len(entries) > limit {
+		return entries[len(entries)-limit:]
+	}
+	return entries
+}
@@ -0,0 +1,115 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_AddAndList(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	for _, cmd := range []string{"ls -la", "du -sh *", "git status"} {
+		if err := store.Add(&Entry{Query: "q", Command: cmd, Executed: true}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	entries, err := store.List(2)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].ID != 2 || entries[1].Command != "git status" {
+		t.Errorf("Expected the two most recent entries in order, got %+v", entries)
+	}
+	if entries[1].Time.IsZero() {
+		t.Error("Expected time to be set")
+	}
+}
+
+func TestStore_Empty(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "missing"))
+
+	entries, err := store.List(10)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected no entries, got %v, %v", entries, err)
+	}
+	if _, err := store.Get(1); err == nil {
+		t.Error("Expected error for unknown id")
+	}
+}
+
+func TestStore_Search(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.Add(&Entry{Query: "show disk hogs", Command: "du -sh * | sort -h"})
+	store.Add(&Entry{Query: "list files", Command: "ls -la"})
+	store.Add(&Entry{Query: "biggest dirs", Command: "du -d1 | sort -n"})
+
+	matches, err := store.Search("DU ", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) != 2 || matches[0].ID != 1 || matches[1].ID != 3 {
+		t.Errorf("Expected entries 1 and 3, got %+v", matches)
+	}
+
+	matches, _ = store.Search("list", 0)
+	if len(matches) != 1 || matches[0].Command != "ls -la" {
+		t.Errorf("Expected query match, got %+v", matches)
+	}
+}
+
+func TestStore_Get(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.Add(&Entry{Command: "first"})
+	store.Add(&Entry{Command: "second", Edits: []string{"secnod"}, ExitCode: 2})
+
+	e, err := store.Get(2)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if e.Command != "second" || e.ExitCode != 2 || len(e.Edits) != 1 {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+}
+
+func TestStore_SkipsCorruptLines(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	store.Add(&Entry{Command: "ok"})
+
+	f, _ := os.OpenFile(filepath.Join(dir, FileName), os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString(`{"id": 2, "comm`)
+	f.Close()
+
+	entries, err := store.List(0)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected the corrupt line to be skipped, got %v, %v", entries, err)
+	}
+}
+
+func TestStore_Bounded(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	entries := make([]Entry, maxEntries)
+	for i := range entries {
+		entries[i] = Entry{ID: i + 1, Command: "x"}
+	}
+	if err := store.rewrite(entries); err != nil {
+		t.Fatalf("rewrite failed: %v", err)
+	}
+
+	store.Add(&Entry{Command: "newest"})
+
+	all, _ := store.List(0)
+	if len(all) != maxEntries {
+		t.Errorf("Expected %d entries, got %d", maxEntries, len(all))
+	}
+	if all[0].ID != 2 || all[len(all)-1].ID != maxEntries+1 {
+		t.Errorf("Expected oldest entry dropped and IDs to keep increasing, got first %d last %d", all[0].ID, all[len(all)-1].ID)
+	}
+}
@@ -0,0 +1,250 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sysContext "github.com/palaforcade/zchat/internal/context"
+)
+
+// PromptVersion is part of every cache key; bump it with every change to the prompts, so
+// that answers to the old ones aren't served. TestPromptVersion fails until it is bumped.
+const PromptVersion = "2"
+
+// cacheContext is the part of the system context that steers the answer
+type cacheContext struct {
+	OS         string
+	Arch       string
+	Shell      string
+	WorkingDir string
+	ContextOff bool
+	Files      []string
+	Samples    []sysContext.FileSample
+	Sections   []sysContext.Section
+}
+
+// CachingClient wraps a Client and serves repeated requests from an on-disk cache.
+// Entries are keyed by the normalized request, provider, model, prompt version and a hash
+// of the system context, so a changed directory or config yields a fresh answer.
+type CachingClient struct {
+	client     Client
+	dir        string
+	provider   string
+	model      string
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	lastKey string
+	lastHit bool
+	lastAge time.Duration
+}
+
+type cacheEntry struct {
+	Created time.Time       `json:"created"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// NewCachingClient wraps client with a cache in dir holding at most maxEntries answers for ttl
+func NewCachingClient(client Client, dir, provider, model string, ttl time.Duration, maxEntries int) *CachingClient {
+	return &CachingClient{
+		client:     client,
+		dir:        dir,
+		provider:   provider,
+		model:      model,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+// GenerateCommand returns a cached command or asks the wrapped client
+func (c *CachingClient) GenerateCommand(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (string, error) {
+	return cached(c, "command", query, sysCtx, func() (string, error) {
+		return c.client.GenerateCommand(ctx, query, sysCtx)
+	})
+}
+
+// ExplainCommand returns a cached explanation or asks the wrapped client
+func (c *CachingClient) ExplainCommand(ctx context.Context, command string, sysCtx *sysContext.SystemContext) (*Explanation, error) {
+	return cached(c, "explain", command, sysCtx, func() (*Explanation, error) {
+		return c.client.ExplainCommand(ctx, command, sysCtx)
+	})
+}
+
+// GeneratePlan returns a cached plan or asks the wrapped client
+func (c *CachingClient) GeneratePlan(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (*Plan, error) {
+	return cached(c, "plan", query, sysCtx, func() (*Plan, error) {
+		return c.client.GeneratePlan(ctx, query, sysCtx)
+	})
+}
+
+// LastHit reports whether the last answer came from the cache, and how old it was
+func (c *CachingClient) LastHit() (bool, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastHit, c.lastAge
+}
+
+// LastKey returns the cache key of the last answer, to Forget it later
+func (c *CachingClient) LastKey() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastKey
+}
+
+// Forget drops an answer from the cache, e.g. because the command it produced failed. It takes
+// the key rather than forgetting the last answer, since an explanation may have come since.
+func (c *CachingClient) Forget(key string) {
+	if key != "" {
+		os.Remove(c.entryPath(key))
+	}
+}
+
+// cached serves a request of the given kind from the cache, or calls fetch and stores the result
+func cached[T any](c *CachingClient, kind, request string, sysCtx *sysContext.SystemContext, fetch func() (T, error)) (T, error) {
+	key := c.key(kind, request, sysCtx)
+
+	c.mu.Lock()
+	c.lastKey, c.lastHit, c.lastAge = key, false, 0
+	c.mu.Unlock()
+
+	var value T
+	if entry, ok := c.read(key); ok && json.Unmarshal(entry.Value, &value) == nil {
+		c.mu.Lock()
+		c.lastHit, c.lastAge = true, time.Since(entry.Created)
+		c.mu.Unlock()
+		return value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	c.write(key, value)
+
+	return value, nil
+}
+
+// key fingerprints everything that influences the answer
+func (c *CachingClient) key(kind, request string, sysCtx *sysContext.SystemContext) string {
+	h := sha256.New()
+	for _, part := range []string{kind, normalizeQuery(request), c.provider, c.model, PromptVersion} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if data, err := json.Marshal(keyContext(sysCtx)); err == nil {
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// keyContext returns what the cache key covers of sysCtx: the platform, the directory and
+// the sections rendered in the prompt. Sections with an Identity count by it alone, so state
+// that drifts between runs, like container status or change counts, doesn't cause misses.
+func keyContext(sysCtx *sysContext.SystemContext) cacheContext {
+	key := cacheContext{
+		OS:         sysCtx.OS,
+		Arch:       sysCtx.Arch,
+		Shell:      sysCtx.Shell,
+		WorkingDir: sysCtx.WorkingDir,
+		ContextOff: sysCtx.ContextOff,
+		Files:      sysCtx.Files,
+		Samples:    sysCtx.Samples,
+	}
+	for _, section := range sysCtx.Sections {
+		if section.Identity != nil {
+			section.Items, section.Identity = section.Identity, nil
+		}
+		if len(section.Items) > 0 {
+			key.Sections = append(key.Sections, section)
+		}
+	}
+	return key
+}
+
+// normalizeQuery folds whitespace and trailing punctuation, leaving case alone since file names are case-sensitive
+func normalizeQuery(query string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(query), " "), "?!.")
+}
+
+func (c *CachingClient) entryPath(key string) string {
+	return filepath.Join(c.dir, key[:32]+".json")
+}
+
+// read loads an entry if it exists and hasn't expired
+func (c *CachingClient) read(key string) (*cacheEntry, bool) {
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || time.Since(entry.Created) > c.ttl {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// write stores an answer and evicts expired and excess entries; the cache is only an
+// optimization, so failures are ignored
+func (c *CachingClient) write(key string, value any) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{Created: time.Now(), Value: raw})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+	if err := os.WriteFile(c.entryPath(key), data, 0600); err != nil {
+		return
+	}
+
+	c.evict()
+}
+
+// evict removes expired entries, then the oldest ones until at most maxEntries remain
+func (c *CachingClient) evict() {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return
+	}
+
+	type cacheFile struct {
+		path    string
+		modTime time.Time
+	}
+	var live []cacheFile
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > c.ttl {
+			os.Remove(path)
+			continue
+		}
+		live = append(live, cacheFile{path, info.ModTime()})
+	}
+
+	if c.maxEntries <= 0 || len(live) <= c.maxEntries {
+		return
+	}
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].modTime.Before(live[j].modTime)
+	})
+	for _, f := range live[:len(live)-c.maxEntries] {
+		os.Remove(f.path)
+	}
+}
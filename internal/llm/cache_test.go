@@ -0,0 +1,223 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sysContext "github.com/palaforcade/zchat/internal/context"
+)
+
+// countingClient answers with a numbered command so cache hits are distinguishable
+type countingClient struct {
+	calls int
+}
+
+func (c *countingClient) GenerateCommand(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (string, error) {
+	c.calls++
+	return fmt.Sprintf("echo %d", c.calls), nil
+}
+
+func (c *countingClient) ExplainCommand(ctx context.Context, command string, sysCtx *sysContext.SystemContext) (*Explanation, error) {
+	c.calls++
+	return &Explanation{Command: command, Summary: fmt.Sprintf("call %d", c.calls)}, nil
+}
+
+func (c *countingClient) GeneratePlan(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (*Plan, error) {
+	c.calls++
+	return &Plan{Steps: []PlanStep{{Description: "step", Command: fmt.Sprintf("echo %d", c.calls)}}}, nil
+}
+
+func newTestCache(t *testing.T, inner Client, maxEntries int) *CachingClient {
+	return NewCachingClient(inner, t.TempDir(), "ollama", "qwen", time.Hour, maxEntries)
+}
+
+func TestCachingClient_Hit(t *testing.T) {
+	inner := &countingClient{}
+	cache := newTestCache(t, inner, 10)
+	sysCtx := &sysContext.SystemContext{WorkingDir: "/tmp", Files: []string{"a.txt"}}
+
+	first, _ := cache.GenerateCommand(context.Background(), "list files", sysCtx)
+	if hit, _ := cache.LastHit(); hit {
+		t.Error("Expected first request to miss")
+	}
+
+	second, _ := cache.GenerateCommand(context.Background(), "  list   files? ", sysCtx)
+	if hit, _ := cache.LastHit(); !hit {
+		t.Error("Expected normalized repeat to hit")
+	}
+	if first != second || inner.calls != 1 {
+		t.Errorf("Expected cached answer %q, got %q after %d calls", first, second, inner.calls)
+	}
+}
+
+func TestCachingClient_KeyIncludesContextAndModel(t *testing.T) {
+	inner := &countingClient{}
+	dir := t.TempDir()
+	cache := NewCachingClient(inner, dir, "ollama", "qwen", time.Hour, 10)
+
+	cache.GenerateCommand(context.Background(), "list files", &sysContext.SystemContext{WorkingDir: "/a"})
+	cache.GenerateCommand(context.Background(), "list files", &sysContext.SystemContext{WorkingDir: "/b"})
+	cache.GenerateCommand(context.Background(), "List files", &sysContext.SystemContext{WorkingDir: "/a"})
+
+	other := NewCachingClient(inner, dir, "ollama", "llama", time.Hour, 10)
+	other.GenerateCommand(context.Background(), "list files", &sysContext.SystemContext{WorkingDir: "/a"})
+
+	if inner.calls != 4 {
+		t.Errorf("Expected every variation to miss, got %d calls", inner.calls)
+	}
+}
+
+func TestCachingClient_KindsAreSeparate(t *testing.T) {
+	inner := &countingClient{}
+	cache := newTestCache(t, inner, 10)
+	sysCtx := &sysContext.SystemContext{}
+
+	cache.GenerateCommand(context.Background(), "ls", sysCtx)
+	exp, _ := cache.ExplainCommand(context.Background(), "ls", sysCtx)
+	again, _ := cache.ExplainCommand(context.Background(), "ls", sysCtx)
+	plan, _ := cache.GeneratePlan(context.Background(), "ls", sysCtx)
+
+	if inner.calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", inner.calls)
+	}
+	if exp.Summary != again.Summary {
+		t.Errorf("Expected cached explanation, got %q and %q", exp.Summary, again.Summary)
+	}
+	if plan.Steps[0].Command != "echo 3" {
+		t.Errorf("Unexpected plan: %+v", plan)
+	}
+}
+
+func TestCachingClient_TTL(t *testing.T) {
+	inner := &countingClient{}
+	cache := NewCachingClient(inner, t.TempDir(), "ollama", "qwen", time.Millisecond, 10)
+	sysCtx := &sysContext.SystemContext{}
+
+	cache.GenerateCommand(context.Background(), "ls", sysCtx)
+	time.Sleep(5 * time.Millisecond)
+	cache.GenerateCommand(context.Background(), "ls", sysCtx)
+
+	if inner.calls != 2 {
+		t.Errorf("Expected expired entry to be refetched, got %d calls", inner.calls)
+	}
+}
+
+func TestCachingClient_Eviction(t *testing.T) {
+	inner := &countingClient{}
+	cache := newTestCache(t, inner, 3)
+	sysCtx := &sysContext.SystemContext{}
+
+	for i := 0; i < 5; i++ {
+		cache.GenerateCommand(context.Background(), fmt.Sprintf("query %d", i), sysCtx)
+		// Distinct mtimes so the oldest entries are evicted first
+		files, _ := filepath.Glob(filepath.Join(cache.dir, "*.json"))
+		for _, f := range files {
+			info, _ := os.Stat(f)
+			os.Chtimes(f, info.ModTime(), info.ModTime().Add(-time.Second))
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cache.dir, "*.json"))
+	if len(files) != 3 {
+		t.Errorf("Expected 3 entries after eviction, got %d", len(files))
+	}
+
+	cache.GenerateCommand(context.Background(), "query 4", sysCtx)
+	if hit, _ := cache.LastHit(); !hit {
+		t.Error("Expected the newest entry to survive eviction")
+	}
+}
+
+func TestCachingClient_Forget(t *testing.T) {
+	inner := &countingClient{}
+	cache := newTestCache(t, inner, 10)
+	sysCtx := &sysContext.SystemContext{}
+
+	cache.GenerateCommand(context.Background(), "ls", sysCtx)
+	key := cache.LastKey()
+	cache.ExplainCommand(context.Background(), "ls", sysCtx)
+	cache.Forget(key)
+	cache.GenerateCommand(context.Background(), "ls", sysCtx)
+
+	if inner.calls != 3 {
+		t.Errorf("Expected forgotten answer to be refetched, got %d calls", inner.calls)
+	}
+	cache.ExplainCommand(context.Background(), "ls", sysCtx)
+	if hit, _ := cache.LastHit(); !hit {
+		t.Error("Expected the explanation to stay cached")
+	}
+}
+
+func TestCachingClient_ErrorsNotCached(t *testing.T) {
+	cache := newTestCache(t, &failingClient{}, 10)
+
+	if _, err := cache.GenerateCommand(context.Background(), "ls", &sysContext.SystemContext{}); err == nil {
+		t.Fatal("Expected error")
+	}
+	files, _ := filepath.Glob(filepath.Join(cache.dir, "*.json"))
+	if len(files) != 0 {
+		t.Errorf("Expected no cache entries after an error, got %d", len(files))
+	}
+}
+
+type failingClient struct{ countingClient }
+
+func (c *failingClient) GenerateCommand(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (string, error) {
+	return "", fmt.Errorf("model unavailable")
+}
+
+func TestCachingClient_KeyUsesSectionIdentity(t *testing.T) {
+	inner := &countingClient{}
+	cache := newTestCache(t, inner, 10)
+	withSections := func(sections ...sysContext.Section) *sysContext.SystemContext {
+		return &sysContext.SystemContext{WorkingDir: "/app", Files: []string{"go.mod"}, Sections: sections}
+	}
+	container := func(status string) sysContext.Section {
+		return sysContext.Section{Provider: "docker", Items: []string{"web  image=nginx  running (" + status + ")"}, Identity: []string{"web nginx"}}
+	}
+	branch := func(name, changes string) sysContext.Section {
+		return sysContext.Section{Provider: "git", Items: []string{"Branch: " + name, "Changes: " + changes}, Identity: []string{"/app", name}}
+	}
+
+	cache.GenerateCommand(context.Background(), "restart web", withSections(container("Up 2 minutes")))
+	cache.GenerateCommand(context.Background(), "restart web", withSections(container("Up 7 minutes"), sysContext.Section{Provider: "history"}))
+	if hit, _ := cache.LastHit(); !hit {
+		t.Error("Expected drifting state and empty sections to leave the key alone")
+	}
+
+	cache.GenerateCommand(context.Background(), "push this branch", withSections(branch("feature-a", "1 modified")))
+	cache.GenerateCommand(context.Background(), "push this branch", withSections(branch("feature-a", "3 modified")))
+	if hit, _ := cache.LastHit(); !hit {
+		t.Error("Expected another change count on the same branch to hit")
+	}
+	cache.GenerateCommand(context.Background(), "push this branch", withSections(branch("feature-b", "3 modified")))
+	if hit, _ := cache.LastHit(); hit {
+		t.Error("Expected another branch to miss")
+	}
+
+	cache.GenerateCommand(context.Background(), "restart web", withSections(sysContext.Section{Provider: "history", Items: []string{"docker compose up"}}))
+	if hit, _ := cache.LastHit(); hit {
+		t.Error("Expected another history section to miss")
+	}
+}
+
+// promptsHash is the SHA-256 of the prompts below as of PromptVersion
+const promptsHash = "fe4dded191d05279699cbc3e1e752e0c7f8504209fa7a727f13302afb6ea409c"
+
+func TestPromptVersion(t *testing.T) {
+	sysCtx := &sysContext.SystemContext{
+		OS: "linux", Arch: "amd64", Shell: "/bin/bash", WorkingDir: "/app", Files: []string{"go.mod"},
+		Samples:  []sysContext.FileSample{{Name: "data.csv", Kind: "csv", Delimiter: ",", Size: 10, Lines: []string{"a,b"}}},
+		Sections: []sysContext.Section{{Provider: "git", Title: "GIT", Items: []string{"branch: main"}}},
+	}
+	prompts := buildSystemPrompt(sysCtx) + buildExplainPrompt(sysCtx) + buildPlanPrompt(sysCtx) + BuildFixQuery("list files", "ls -z", 2, "ls: invalid option")
+
+	if hash := fmt.Sprintf("%x", sha256.Sum256([]byte(prompts))); hash != promptsHash {
+		t.Errorf("The prompts changed: bump PromptVersion and set promptsHash to %s", hash)
+	}
+}
@@ -12,6 +12,8 @@ import (
 
 type Client interface {
 	GenerateCommand(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (string, error)
+	ExplainCommand(ctx context.Context, command string, sysCtx *sysContext.SystemContext) (*Explanation, error)
+	GeneratePlan(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (*Plan, error)
 }
 
 type AnthropicClient struct {
@@ -33,13 +35,46 @@ func NewAnthropicClient(apiKey, model string) *AnthropicClient {
 
 // GenerateCommand generates a shell command from a natural language query
 func (c *AnthropicClient) GenerateCommand(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (string, error) {
-	// Build system prompt
-	systemPrompt := buildSystemPrompt(sysCtx)
+	responseText, err := c.complete(ctx, buildSystemPrompt(sysCtx), query, 1024)
+	if err != nil {
+		return "", err
+	}
+
+	// Parse and clean the response
+	command, err := parseCommandFromResponse(responseText)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse command: %w", err)
+	}
 
+	return command, nil
+}
+
+// ExplainCommand asks for a structured breakdown of an existing command
+func (c *AnthropicClient) ExplainCommand(ctx context.Context, command string, sysCtx *sysContext.SystemContext) (*Explanation, error) {
+	responseText, err := c.complete(ctx, buildExplainPrompt(sysCtx), command, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExplanation(responseText)
+}
+
+// GeneratePlan generates an ordered list of commands for a multi-step goal
+func (c *AnthropicClient) GeneratePlan(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (*Plan, error) {
+	responseText, err := c.complete(ctx, buildPlanPrompt(sysCtx), query, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePlan(responseText)
+}
+
+// complete sends a system prompt and a single user message and returns the response text
+func (c *AnthropicClient) complete(ctx context.Context, systemPrompt, userMessage string, maxTokens int64) (string, error) {
 	// Create message request
 	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     anthropic.Model(c.model),
-		MaxTokens: 1024,
+		MaxTokens: maxTokens,
 		System: []anthropic.TextBlockParam{
 			{
 				Type: "text",
@@ -47,7 +82,7 @@ func (c *AnthropicClient) GenerateCommand(ctx context.Context, query string, sys
 			},
 		},
 		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(query)),
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userMessage)),
 		},
 	})
 
@@ -60,13 +95,5 @@ func (c *AnthropicClient) GenerateCommand(ctx context.Context, query string, sys
 		return "", fmt.Errorf("received empty response from API")
 	}
 
-	responseText := message.Content[0].Text
-
-	// Parse and clean the response
-	command, err := parseCommandFromResponse(responseText)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse command: %w", err)
-	}
-
-	return command, nil
+	return message.Content[0].Text, nil
 }
@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+)
+
+// Explanation is a structured breakdown of a shell command
+type Explanation struct {
+	Command string        `json:"command"`
+	Summary string        `json:"summary"`
+	Stages  []Stage       `json:"stages"`
+	Reads   []string      `json:"reads"`
+	Writes  []string      `json:"writes"`
+	Network []string      `json:"network"` // hosts or kinds of network access; empty means none
+	Risk    executor.Risk `json:"risk"`    // the model's verdict, with the executor's levels
+}
+
+// Stage is one command in a pipeline or command list
+type Stage struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	Flags       []Flag `json:"flags"`
+}
+
+// Flag is an option or argument and what it does
+type Flag struct {
+	Flag    string `json:"flag"`
+	Meaning string `json:"meaning"`
+}
+
+// buildExplainPrompt asks for a JSON breakdown of a command. Only the platform and shell
+// are included: explaining a command doesn't need the directory listing or other context.
+func buildExplainPrompt(sysCtx *context.SystemContext) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are a command-line expert. Explain the shell command the user gives you. It will NOT be executed.\n\n")
+	sb.WriteString("CRITICAL RULES:\n")
+	sb.WriteString("- Respond with ONLY a JSON object, no markdown and no code blocks\n")
+	sb.WriteString("- Split the command into stages at pipes, &&, || and ;\n")
+	sb.WriteString("- Explain every flag and argument of every stage\n")
+	sb.WriteString("- List files and directories it reads and writes (including deletions), and any network access\n")
+	sb.WriteString("- Rate the risk as low (read-only), medium (modifies files or state) or high (destructive, privileged, or runs remote code)\n")
+	sb.WriteString("\nJSON SHAPE:\n")
+	sb.WriteString(`{"summary": "one sentence", "stages": [{"command": "...", "description": "...", "flags": [{"flag": "...", "meaning": "..."}]}], "reads": ["..."], "writes": ["..."], "network": ["..."], "risk": {"level": "low|medium|high", "reasons": ["..."]}}`)
+	sb.WriteString("\n\nSYSTEM CONTEXT:\n")
+	sb.WriteString(fmt.Sprintf("- Operating System: %s\n", sysCtx.OS))
+	sb.WriteString(fmt.Sprintf("- Shell: %s\n", sysCtx.Shell))
+
+	return sb.String()
+}
+
+// parseExplanation extracts the JSON object from the model's response
+func parseExplanation(response string) (*Explanation, error) {
+	start := strings.IndexByte(response, '{')
+	end := strings.LastIndexByte(response, '}')
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object in response")
+	}
+
+	var exp Explanation
+	if err := json.Unmarshal([]byte(response[start:end+1]), &exp); err != nil {
+		return nil, fmt.Errorf("failed to parse explanation: %w", err)
+	}
+	exp.Risk.Level = strings.ToLower(strings.TrimSpace(exp.Risk.Level))
+
+	// Keep JSON output stable for consumers: empty lists rather than null
+	exp.Stages = nonNil(exp.Stages)
+	exp.Reads = nonNil(exp.Reads)
+	exp.Writes = nonNil(exp.Writes)
+	exp.Network = nonNil(exp.Network)
+	exp.Risk.Reasons = nonNil(exp.Risk.Reasons)
+	for i := range exp.Stages {
+		exp.Stages[i].Flags = nonNil(exp.Stages[i].Flags)
+	}
+
+	return &exp, nil
+}
+
+// nonNil returns an empty slice in place of nil
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+)
+
+func TestParseExplanation(t *testing.T) {
+	response := "```json\n" + `{
+		"summary": "Counts lines in log files",
+		"stages": [
+			{"command": "cat *.log", "description": "Prints all log files", "flags": [{"flag": "*.log", "meaning": "every .log file"}]},
+			{"command": "wc -l", "description": "Counts lines", "flags": [{"flag": "-l", "meaning": "count lines"}]}
+		],
+		"reads": ["*.log"],
+		"writes": [],
+		"network": [],
+		"risk": {"level": "Low", "reasons": ["read-only"]}
+	}` + "\n```"
+
+	exp, err := parseExplanation(response)
+	if err != nil {
+		t.Fatalf("parseExplanation failed: %v", err)
+	}
+
+	if len(exp.Stages) != 2 {
+		t.Fatalf("Expected 2 stages, got %d", len(exp.Stages))
+	}
+	if exp.Stages[1].Flags[0].Flag != "-l" {
+		t.Errorf("Expected -l flag, got %q", exp.Stages[1].Flags[0].Flag)
+	}
+	if exp.Risk.Level != executor.RiskLow {
+		t.Errorf("Expected normalized risk level 'low', got %q", exp.Risk.Level)
+	}
+}
+
+func TestParseExplanation_EmptyLists(t *testing.T) {
+	exp, err := parseExplanation(`{"summary": "x", "stages": [{"command": "ls"}]}`)
+	if err != nil {
+		t.Fatalf("parseExplanation failed: %v", err)
+	}
+	if exp.Reads == nil || exp.Network == nil || exp.Risk.Reasons == nil || exp.Stages[0].Flags == nil {
+		t.Errorf("Expected missing lists to be empty, not nil: %+v", exp)
+	}
+}
+
+func TestParseExplanation_Invalid(t *testing.T) {
+	for _, response := range []string{"", "this command lists files", "{not json}"} {
+		if _, err := parseExplanation(response); err == nil {
+			t.Errorf("Expected error for %q", response)
+		}
+	}
+}
+
+func TestBuildExplainPrompt(t *testing.T) {
+	sysCtx := &context.SystemContext{
+		WorkingDir: "/home/user/client-project",
+		Files:      []string{"secret-plans.txt"},
+		Shell:      "/bin/bash",
+		OS:         "linux",
+	}
+
+	prompt := buildExplainPrompt(sysCtx)
+
+	if !strings.Contains(prompt, "Shell: /bin/bash") {
+		t.Error("Expected shell in explain prompt")
+	}
+	if strings.Contains(prompt, "client-project") || strings.Contains(prompt, "secret-plans.txt") {
+		t.Error("Expected directory context to be left out of the explain prompt")
+	}
+}
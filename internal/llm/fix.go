@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildFixQuery turns a failed command into a request for a corrected one. It is sent
+// through GenerateCommand, so every provider and the usual system context apply.
+func BuildFixQuery(query, command string, exitCode int, output string) string {
+	var sb strings.Builder
+
+	sb.WriteString("The previous command failed. Reply with a corrected command that accomplishes the original request.\n")
+	if query != "" {
+		sb.WriteString(fmt.Sprintf("Original request: %s\n", query))
+	}
+	sb.WriteString(fmt.Sprintf("Failed command: %s\n", command))
+	if exitCode >= 0 {
+		sb.WriteString(fmt.Sprintf("Exit status: %d\n", exitCode))
+	} else {
+		sb.WriteString("Exit status: killed or timed out\n")
+	}
+
+	if strings.TrimSpace(output) == "" {
+		sb.WriteString("Output: (none)\n")
+	} else {
+		sb.WriteString("Output (last lines):\n<<<OUTPUT\n")
+		sb.WriteString(output)
+		sb.WriteString("\nOUTPUT>>>\n")
+	}
+
+	sb.WriteString("Do not repeat the failed command unchanged.")
+
+	return sb.String()
+}
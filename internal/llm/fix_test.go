@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildFixQuery(t *testing.T) {
+	q := BuildFixQuery("count lines in data.csv", "wc -l data.cvs", 1, "wc: data.cvs: No such file or directory")
+
+	for _, want := range []string{
+		"Original request: count lines in data.csv",
+		"Failed command: wc -l data.cvs",
+		"Exit status: 1",
+		"<<<OUTPUT\nwc: data.cvs: No such file or directory\nOUTPUT>>>",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("Expected query to contain %q, got:\n%s", want, q)
+		}
+	}
+}
+
+func TestBuildFixQuery_KilledNoOutput(t *testing.T) {
+	q := BuildFixQuery("", "sleep 100", -1, "")
+
+	if strings.Contains(q, "Original request") {
+		t.Error("Expected no original request line when the query is unknown")
+	}
+	if !strings.Contains(q, "killed or timed out") {
+		t.Error("Expected killed status")
+	}
+	if !strings.Contains(q, "Output: (none)") {
+		t.Error("Expected empty output marker")
+	}
+}
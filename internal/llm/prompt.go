@@ -16,11 +16,56 @@ func buildSystemPrompt(sysCtx *context.SystemContext) string {
 	sb.WriteString("- Output ONLY the command itself, nothing else\n")
 	sb.WriteString("- No explanations, no markdown, no code blocks, no backticks\n")
 	sb.WriteString("- The command will be executed directly in the shell\n")
-	sb.WriteString("- Make sure the command is safe and correct\n\n")
+	sb.WriteString("- Make sure the command is safe and correct\n")
+	sb.WriteString(shellSyntaxRule(sysCtx.Shell))
+	sb.WriteString("\n")
+	writeSystemContext(&sb, sysCtx)
+
+	sb.WriteString("\nGenerate the appropriate command for the user's request.")
+
+	return sb.String()
+}
+
+// writeSystemContext writes the platform, directory and provider context shared by all prompts
+func writeSystemContext(sb *strings.Builder, sysCtx *context.SystemContext) {
 	sb.WriteString("SYSTEM CONTEXT:\n")
 	sb.WriteString(fmt.Sprintf("- Operating System: %s\n", sysCtx.OS))
 	sb.WriteString(fmt.Sprintf("- Architecture: %s\n", sysCtx.Arch))
 	sb.WriteString(fmt.Sprintf("- Shell: %s\n", sysCtx.Shell))
+
+	if sysCtx.ContextOff {
+		sb.WriteString("- Current Directory: (withheld by .zchatignore)\n")
+		sb.WriteString("- Available Files: (withheld by .zchatignore)\n")
+	} else {
+		writeDirectoryContext(sb, sysCtx)
+
+		if len(sysCtx.Samples) > 0 {
+			sb.WriteString("\nFILE PREVIEWS (truncated samples of files named in the request):\n")
+			for _, sample := range sysCtx.Samples {
+				writeFileSample(sb, sample)
+			}
+		}
+	}
+
+	for _, section := range sysCtx.Sections {
+		writeSection(sb, section)
+	}
+}
+
+// shellSyntaxRule tells the model to use non-POSIX syntax when the shell requires it
+func shellSyntaxRule(shell string) string {
+	switch context.ShellName(shell) {
+	case "fish":
+		return "- The shell is fish: use fish syntax (set -x VAR value, (cmd) for command substitution, no VAR=value prefixes or $(...))\n"
+	case "pwsh":
+		return "- The shell is PowerShell: use PowerShell cmdlets and syntax, not bash\n"
+	default:
+		return ""
+	}
+}
+
+// writeDirectoryContext renders the working directory and its visible files
+func writeDirectoryContext(sb *strings.Builder, sysCtx *context.SystemContext) {
 	sb.WriteString(fmt.Sprintf("- Current Directory: %s\n", sysCtx.WorkingDir))
 
 	if len(sysCtx.Files) > 0 {
@@ -28,10 +73,41 @@ func buildSystemPrompt(sysCtx *context.SystemContext) string {
 	} else {
 		sb.WriteString("- Available Files: (none visible)\n")
 	}
+}
 
-	sb.WriteString("\nGenerate the appropriate command for the user's request.")
+// writeSection renders a provider's section between delimiters named after the provider
+func writeSection(sb *strings.Builder, section context.Section) {
+	tag := strings.ToUpper(section.Provider)
 
-	return sb.String()
+	sb.WriteString(fmt.Sprintf("\n%s:\n", section.Title))
+	sb.WriteString(fmt.Sprintf("<<<%s\n", tag))
+	for _, item := range section.Items {
+		sb.WriteString(item)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("%s>>>\n", tag))
+}
+
+// writeFileSample renders a single file preview
+func writeFileSample(sb *strings.Builder, sample context.FileSample) {
+	switch sample.Kind {
+	case "binary":
+		sb.WriteString(fmt.Sprintf("--- %s (binary, %s, %d bytes)\n", sample.Name, sample.FileType, sample.Size))
+	case "json":
+		sb.WriteString(fmt.Sprintf("--- %s (json, %d bytes)\n", sample.Name, sample.Size))
+		if len(sample.Keys) > 0 {
+			sb.WriteString(fmt.Sprintf("Top-level keys: %s\n", strings.Join(sample.Keys, ", ")))
+		}
+	case "csv", "tsv":
+		sb.WriteString(fmt.Sprintf("--- %s (%s, delimiter %q, %d bytes; first line is the header)\n", sample.Name, sample.Kind, sample.Delimiter, sample.Size))
+	default:
+		sb.WriteString(fmt.Sprintf("--- %s (%s, %d bytes; first lines)\n", sample.Name, sample.Kind, sample.Size))
+	}
+
+	for _, line := range sample.Lines {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
 }
 
 // parseCommandFromResponse cleans up the LLM response and extracts the command
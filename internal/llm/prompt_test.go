@@ -161,3 +161,145 @@ func TestParseCommandFromResponse_OnlyWhitespace(t *testing.T) {
 		t.Error("Expected error for whitespace-only response")
 	}
 }
+
+func TestBuildSystemPrompt_History(t *testing.T) {
+	sysCtx := &context.SystemContext{
+		OS:         "linux",
+		Arch:       "amd64",
+		Shell:      "/bin/bash",
+		WorkingDir: "/srv",
+		Sections: []context.Section{
+			{
+				Provider: "history",
+				Title:    "RECENT SHELL HISTORY (oldest first)",
+				Items:    []string{"rsync -a prod/ backup/", "ls backup"},
+			},
+		},
+	}
+
+	prompt := buildSystemPrompt(sysCtx)
+
+	if !strings.Contains(prompt, "RECENT SHELL HISTORY") {
+		t.Error("Prompt should contain a history section")
+	}
+
+	if !strings.Contains(prompt, "<<<HISTORY\nrsync -a prod/ backup/\nls backup\nHISTORY>>>") {
+		t.Error("Prompt should list history entries inside delimiters")
+	}
+}
+
+func TestBuildSystemPrompt_NoHistory(t *testing.T) {
+	sysCtx := &context.SystemContext{OS: "linux", Shell: "/bin/bash"}
+
+	prompt := buildSystemPrompt(sysCtx)
+
+	if strings.Contains(prompt, "HISTORY") {
+		t.Error("Prompt should omit the history section when there is no history")
+	}
+}
+
+func TestBuildSystemPrompt_FileSamples(t *testing.T) {
+	sysCtx := &context.SystemContext{
+		OS:    "linux",
+		Shell: "/bin/bash",
+		Samples: []context.FileSample{
+			{Name: "data.csv", Kind: "csv", Delimiter: ";", Size: 42, Lines: []string{"id;price", "1;2.5"}},
+			{Name: "config.json", Kind: "json", Size: 10, Keys: []string{"name", "version"}},
+			{Name: "logo.png", Kind: "binary", FileType: "PNG image", Size: 100},
+		},
+	}
+
+	prompt := buildSystemPrompt(sysCtx)
+
+	expected := []string{
+		"FILE PREVIEWS",
+		`--- data.csv (csv, delimiter ";", 42 bytes; first line is the header)`,
+		"id;price\n1;2.5\n",
+		"Top-level keys: name, version",
+		"--- logo.png (binary, PNG image, 100 bytes)",
+	}
+	for _, s := range expected {
+		if !strings.Contains(prompt, s) {
+			t.Errorf("Prompt should contain %q", s)
+		}
+	}
+}
+
+func TestBuildSystemPrompt_ContextOff(t *testing.T) {
+	sysCtx := &context.SystemContext{
+		OS:         "linux",
+		Shell:      "/bin/bash",
+		ContextOff: true,
+	}
+
+	prompt := buildSystemPrompt(sysCtx)
+
+	if !strings.Contains(prompt, "(withheld by .zchatignore)") {
+		t.Error("Prompt should state that directory context is withheld")
+	}
+
+	if strings.Contains(prompt, "(none visible)") {
+		t.Error("Prompt should not claim the directory is empty when context is off")
+	}
+}
+
+func TestBuildSystemPrompt_ContextOffNoPreviews(t *testing.T) {
+	sysCtx := &context.SystemContext{
+		OS:         "linux",
+		Shell:      "/bin/bash",
+		ContextOff: true,
+		Samples:    []context.FileSample{{Name: "notes.txt", Kind: "text", Size: 7, Lines: []string{"private"}}},
+	}
+
+	prompt := buildSystemPrompt(sysCtx)
+
+	if strings.Contains(prompt, "FILE PREVIEWS") || strings.Contains(prompt, "private") {
+		t.Errorf("Prompt should not preview files when context is off, got:\n%s", prompt)
+	}
+}
+
+func TestBuildSystemPrompt_ShellSyntax(t *testing.T) {
+	testCases := []struct {
+		shell    string
+		expected string
+	}{
+		{"/usr/bin/fish", "use fish syntax"},
+		{"/usr/bin/pwsh", "PowerShell"},
+	}
+
+	for _, tc := range testCases {
+		prompt := buildSystemPrompt(&context.SystemContext{Shell: tc.shell})
+		if !strings.Contains(prompt, tc.expected) {
+			t.Errorf("Prompt for %s should contain %q", tc.shell, tc.expected)
+		}
+	}
+
+	prompt := buildSystemPrompt(&context.SystemContext{Shell: "/bin/bash"})
+	if strings.Contains(prompt, "The shell is") {
+		t.Error("Prompt for POSIX shells should not add syntax rules")
+	}
+}
+
+func TestBuildSystemPrompt_Sections(t *testing.T) {
+	sysCtx := &context.SystemContext{
+		Shell: "/bin/zsh",
+		Sections: []context.Section{
+			{Provider: "git", Title: "GIT REPOSITORY", Items: []string{"Branch: main"}},
+			{Provider: "aliases", Title: "USER ALIASES AND FUNCTIONS", Items: []string{"alias k=kubectl"}},
+		},
+	}
+
+	prompt := buildSystemPrompt(sysCtx)
+
+	if !strings.Contains(prompt, "GIT REPOSITORY:\n<<<GIT\nBranch: main\nGIT>>>") {
+		t.Error("Prompt should render the git section between delimiters")
+	}
+
+	if !strings.Contains(prompt, "<<<ALIASES\nalias k=kubectl\nALIASES>>>") {
+		t.Error("Prompt should render the aliases section between delimiters")
+	}
+
+	if strings.Index(prompt, "<<<GIT") > strings.Index(prompt, "<<<ALIASES") {
+		t.Error("Sections should be rendered in order")
+	}
+}
@@ -0,0 +1,20 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildSnippetQuery asks for a saved command with its missing {{placeholders}} filled in from
+// the user's words. Like BuildFixQuery, it goes through GenerateCommand with the usual context.
+func BuildSnippetQuery(command string, missing []string, request string) string {
+	var sb strings.Builder
+
+	sb.WriteString("Fill in the placeholders of this saved command and reply with the complete command.\n")
+	sb.WriteString(fmt.Sprintf("Saved command: %s\n", command))
+	sb.WriteString(fmt.Sprintf("Placeholders to fill: %s\n", strings.Join(missing, ", ")))
+	sb.WriteString(fmt.Sprintf("What to fill them with: %s\n", request))
+	sb.WriteString("Keep the rest of the command unchanged. Replace every {{placeholder}}; do not leave any braces.")
+
+	return sb.String()
+}
@@ -0,0 +1,20 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSnippetQuery(t *testing.T) {
+	q := BuildSnippetQuery("du -sh {{path}}/* | sort -rh | head -n {{count}}", []string{"path", "count"}, "top 5 in /var")
+
+	for _, want := range []string{
+		"Saved command: du -sh {{path}}/* | sort -rh | head -n {{count}}",
+		"Placeholders to fill: path, count",
+		"What to fill them with: top 5 in /var",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("Expected query to contain %q, got:\n%s", want, q)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Mask is the placeholder substituted for redacted secrets
+const Mask = "[REDACTED]"
+
+// entropyThreshold is the Shannon entropy (bits per character) above which a token looks random
+const entropyThreshold = 4.0
+
+// minEntropyRun is the shortest separator-free run a high-entropy token must contain,
+// so that hyphenated identifiers such as pod or image names are left alone
+const minEntropyRun = 16
+
+// Rule is a named pattern whose matches are considered secrets.
+// If the pattern has a capture group named "secret", only that group is masked.
+// If Check is set, a match is only masked when Check returns true.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Check   func(string) bool
+}
+
+// Finding records a single masked secret
+type Finding struct {
+	Rule  string
+	Value string
+}
+
+type Redactor struct {
+	rules []Rule
+}
+
+// defaultRules are the built-in secret shapes
+var defaultRules = []Rule{
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "anthropic-key", Pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{10,}`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{Name: "url-password", Pattern: regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:(?P<secret>[^\s@/]+)@`)},
+	{Name: "secret-assignment", Pattern: regexp.MustCompile(`(?i)\b[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_KEY|APIKEY|ACCESS_KEY)[A-Z0-9_]*\s*[=:]\s*["']?(?P<secret>[^\s"']+)`)},
+	{Name: "high-entropy", Pattern: regexp.MustCompile(`[A-Za-z0-9+/=_-]{24,}`), Check: isHighEntropy},
+}
+
+// New creates a redactor with the built-in rules
+func New() *Redactor {
+	rules := make([]Rule, len(defaultRules))
+	copy(rules, defaultRules)
+	return &Redactor{rules: rules}
+}
+
+// AddPattern registers a user-supplied regular expression as an additional secret shape
+func (r *Redactor) AddPattern(expr string) error {
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid redaction pattern %q: %w", expr, err)
+	}
+
+	r.rules = append(r.rules, Rule{Name: "custom", Pattern: pattern})
+	return nil
+}
+
+// Redact masks every secret in s and returns the cleaned string along with what was masked
+func (r *Redactor) Redact(s string) (string, []Finding) {
+	var findings []Finding
+
+	for _, rule := range r.rules {
+		s = maskRule(s, rule, &findings)
+	}
+
+	return s, findings
+}
+
+// String masks every secret in s, discarding the findings
+func (r *Redactor) String(s string) string {
+	cleaned, _ := r.Redact(s)
+	return cleaned
+}
+
+// maskRule replaces matches of a single rule, honoring the "secret" capture group
+func maskRule(s string, rule Rule, findings *[]Finding) string {
+	group := rule.Pattern.SubexpIndex("secret")
+
+	matches := rule.Pattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		if strings.Contains(s[start:end], Mask) {
+			continue
+		}
+		if rule.Check != nil && !rule.Check(s[start:end]) {
+			continue
+		}
+		*findings = append(*findings, Finding{Rule: rule.Name, Value: s[start:end]})
+		out = append(out, s[last:start]...)
+		out = append(out, Mask...)
+		last = end
+	}
+	out = append(out, s[last:]...)
+
+	return string(out)
+}
+
+// isHighEntropy reports whether a token looks like a random credential rather than a word, path or hash
+func isHighEntropy(token string) bool {
+	hasDigit, hasLetter, onlyHex := false, false, true
+	run, longestRun := 0, 0
+	for _, c := range token {
+		if c == '-' || c == '_' || c == '/' {
+			run = 0
+		} else {
+			run++
+			longestRun = max(longestRun, run)
+		}
+
+		switch {
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			hasLetter = true
+		case (c >= 'g' && c <= 'z') || (c >= 'G' && c <= 'Z'):
+			hasLetter = true
+			onlyHex = false
+		case c != '-':
+			onlyHex = false
+		}
+	}
+
+	// Hex digests and UUIDs are common in commands and never secrets on their own
+	if !hasDigit || !hasLetter || onlyHex || longestRun < minEntropyRun {
+		return false
+	}
+
+	return shannonEntropy(token) > entropyThreshold
+}
+
+// shannonEntropy computes the per-character Shannon entropy of s
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+
+	var entropy float64
+	n := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
@@ -0,0 +1,21 @@
+package shellinit
+
+// bashScript binds a key with `bind -x`, which lets a shell function rewrite the line being
+// edited through READLINE_LINE and READLINE_POINT.
+// Arguments: the quoted zchat binary, then the key sequence.
+const bashScript = `# zchat integration for bash. Add to ~/.bashrc:  eval "$(zchat init bash)"
+_zchat_widget() {
+  local request=$READLINE_LINE
+  [[ -z ${request//[[:space:]]/} ]] && return 0
+  local cmd
+  cmd=$(%[1]s --print -- "$request")
+  if [[ $? -eq 0 && -n $cmd ]]; then
+    READLINE_LINE=$cmd
+    READLINE_POINT=${#READLINE_LINE}
+  fi
+}
+# Line editing only exists in interactive shells
+if [[ $- == *i* ]]; then
+  bind -x '"%[2]s": _zchat_widget'
+fi
+`
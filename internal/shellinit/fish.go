@@ -0,0 +1,17 @@
+package shellinit
+
+// fishScript binds a key to a function that swaps the command line for the generated command
+// with `commandline -r`.
+// Arguments: the quoted zchat binary, then the key sequence.
+const fishScript = `# zchat integration for fish. Add to ~/.config/fish/config.fish:  zchat init fish | source
+function _zchat_widget
+    set -l request (commandline | string collect)
+    string trim -- "$request" | string length -q; or return 0
+    set -l cmd (%[1]s --print -- "$request")
+    and test -n "$cmd"
+    and commandline -r -- (string join \n -- $cmd)
+    commandline -f repaint
+end
+bind %[2]s _zchat_widget
+bind -M insert %[2]s _zchat_widget
+`
@@ -0,0 +1,36 @@
+package shellinit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultKey is the key that turns the request on the command line into a command (Alt-z)
+const DefaultKey = `\ez`
+
+// Shells are the shells with an integration script
+var Shells = []string{"zsh", "bash", "fish"}
+
+// Script returns the integration script for shell. binary is the zchat executable the script calls.
+func Script(shell, binary string) (string, error) {
+	switch shell {
+	case "zsh":
+		return fmt.Sprintf(zshScript, quote(binary), DefaultKey), nil
+	case "bash":
+		return fmt.Sprintf(bashScript, quote(binary), DefaultKey), nil
+	case "fish":
+		return fmt.Sprintf(fishScript, quoteFish(binary), DefaultKey), nil
+	default:
+		return "", fmt.Errorf("unsupported shell: %s (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+}
+
+// quote single-quotes s for POSIX-like shells
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish single-quotes s for fish, where backslash escapes quotes and itself inside quotes
+func quoteFish(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,78 @@
+package shellinit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScript_Zsh(t *testing.T) {
+	script, err := Script("zsh", "/usr/local/bin/zchat")
+	if err != nil {
+		t.Fatalf("Script() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"zle -N _zchat_widget",
+		`bindkey '\ez' _zchat_widget`,
+		`'/usr/local/bin/zchat' --print -- "$request"`,
+		"BUFFER=$cmd",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestScript_Unsupported(t *testing.T) {
+	if _, err := Script("tcsh", "zchat"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	testCases := map[string]string{
+		"/usr/bin/zchat":      "'/usr/bin/zchat'",
+		"/home/o'neil/zchat":  `'/home/o'\''neil/zchat'`,
+		"/path with space/zc": "'/path with space/zc'",
+	}
+	for input, expected := range testCases {
+		if got := quote(input); got != expected {
+			t.Errorf("quote(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestScript_Bash(t *testing.T) {
+	script, err := Script("bash", "/usr/local/bin/zchat")
+	if err != nil {
+		t.Fatalf("Script() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`bind -x '"\ez": _zchat_widget'`,
+		`'/usr/local/bin/zchat' --print -- "$request"`,
+		"READLINE_LINE=$cmd",
+		"READLINE_POINT=${#READLINE_LINE}",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestScript_Fish(t *testing.T) {
+	script, err := Script("fish", `/opt/o'neil\bin/zchat`)
+	if err != nil {
+		t.Fatalf("Script() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`bind \ez _zchat_widget`,
+		`'/opt/o\'neil\\bin/zchat' --print -- "$request"`,
+		"commandline -r -- (string join \\n -- $cmd)",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
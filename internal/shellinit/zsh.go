@@ -0,0 +1,22 @@
+package shellinit
+
+// zshScript defines a ZLE widget that replaces the request on the command line with the
+// generated command, so it can be edited and lands in the shell's own history when run.
+// Arguments: the quoted zchat binary, then the key sequence.
+const zshScript = `# zchat integration for zsh. Add to ~/.zshrc:  eval "$(zchat init zsh)"
+_zchat_widget() {
+  local request=$BUFFER
+  [[ -z ${request//[[:space:]]/} ]] && return 0
+  # Let zchat's messages on stderr print below the prompt instead of over it
+  zle -I
+  local cmd
+  cmd=$(%[1]s --print -- "$request")
+  if [[ $? -eq 0 && -n $cmd ]]; then
+    BUFFER=$cmd
+    CURSOR=${#BUFFER}
+  fi
+  zle reset-prompt
+}
+zle -N _zchat_widget
+bindkey '%[2]s' _zchat_widget
+`
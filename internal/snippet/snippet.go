@@ -0,0 +1,227 @@
+package snippet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName is the snippets file, kept next to config.yaml so it can be shared in git
+	FileName = "snippets.yaml"
+	// maxMatches bounds how many snippets are offered to the model for one query
+	maxMatches = 5
+)
+
+// placeholderPattern matches {{name}} placeholders, allowing spaces inside the braces
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// paramPattern matches a placeholder name given as name=value on the command line
+var paramPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// namePattern restricts snippet names to something easy to type on the command line
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Snippet is a named command, optionally with {{name}} placeholders
+type Snippet struct {
+	Name        string `yaml:"name"`
+	Command     string `yaml:"command"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// file is the on-disk layout of the snippets file
+type file struct {
+	Snippets []Snippet `yaml:"snippets"`
+}
+
+// Library is the set of saved snippets, in file order
+type Library struct {
+	path     string
+	Snippets []Snippet
+}
+
+// Load reads the snippets file in dir. A missing file is an empty library.
+func Load(dir string) (*Library, error) {
+	lib := &Library{path: filepath.Join(dir, FileName)}
+
+	data, err := os.ReadFile(lib.path)
+	if os.IsNotExist(err) {
+		return lib, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", lib.path, err)
+	}
+	lib.Snippets = f.Snippets
+	return lib, nil
+}
+
+// Get returns the snippet called name
+func (l *Library) Get(name string) (*Snippet, error) {
+	for i := range l.Snippets {
+		if l.Snippets[i].Name == name {
+			return &l.Snippets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no snippet named %q", name)
+}
+
+// ValidName reports whether name can be used for a snippet
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Put adds s, replacing any snippet with the same name in place
+func (l *Library) Put(s Snippet) error {
+	if !ValidName(s.Name) {
+		return fmt.Errorf("invalid snippet name %q (use letters, digits, '-', '_' and '.')", s.Name)
+	}
+	if strings.TrimSpace(s.Command) == "" {
+		return fmt.Errorf("snippet %q has no command", s.Name)
+	}
+
+	for i := range l.Snippets {
+		if l.Snippets[i].Name == s.Name {
+			l.Snippets[i] = s
+			return nil
+		}
+	}
+	l.Snippets = append(l.Snippets, s)
+	return nil
+}
+
+// Save writes the library back to its file
+func (l *Library) Save() error {
+	data, err := yaml.Marshal(file{Snippets: l.Snippets})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, data, 0644)
+}
+
+// Match returns the snippets that share words with query, best matches first
+func (l *Library) Match(query string) []Snippet {
+	words := wordSet(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		snippet Snippet
+		score   int
+	}
+	var matches []scored
+	for _, s := range l.Snippets {
+		score := 0
+		for w := range wordSet(s.Name + " " + s.Description) {
+			if words[w] {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{s, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	var result []Snippet
+	for i := 0; i < len(matches) && i < maxMatches; i++ {
+		result = append(result, matches[i].snippet)
+	}
+	return result
+}
+
+// stopWords are too common in requests to say anything about which snippet fits
+var stopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "in": true, "of": true, "on": true, "to": true, "for": true,
+	"and": true, "or": true, "all": true, "me": true, "my": true, "show": true, "with": true, "from": true,
+}
+
+// wordSet splits text into lowercase words, dropping stop words and single letters
+func wordSet(text string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		if len(w) > 1 && !stopWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// Placeholders returns the distinct placeholder names in command, in order of appearance
+func Placeholders(command string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(command, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Fill substitutes values into command's placeholders and returns the names left without a value
+func Fill(command string, values map[string]string) (string, []string) {
+	filled := placeholderPattern.ReplaceAllStringFunc(command, func(m string) string {
+		name := placeholderPattern.FindStringSubmatch(m)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return m
+	})
+	return filled, Placeholders(filled)
+}
+
+// Parameterize replaces each value in command with its {{name}} placeholder, for saving a
+// concrete command as a reusable snippet. It fails if a value doesn't appear in the command.
+func Parameterize(command string, values map[string]string) (string, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	// Longest values first, so /var/log is replaced before /var
+	sort.Slice(names, func(i, j int) bool {
+		if len(values[names[i]]) != len(values[names[j]]) {
+			return len(values[names[i]]) > len(values[names[j]])
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		value := values[name]
+		if value == "" || !strings.Contains(command, value) {
+			return "", fmt.Errorf("%q does not appear in the command", value)
+		}
+		command = strings.ReplaceAll(command, value, "{{"+name+"}}")
+	}
+	return command, nil
+}
+
+// ParseArgs splits name=value arguments from the rest, which are returned as free text
+func ParseArgs(args []string) (map[string]string, []string) {
+	values := map[string]string{}
+	var rest []string
+	for _, arg := range args {
+		if name, value, ok := strings.Cut(arg, "="); ok && paramPattern.MatchString(name) {
+			values[name] = value
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return values, rest
+}
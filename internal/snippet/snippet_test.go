@@ -0,0 +1,163 @@
+package snippet
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	lib, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(lib.Snippets) != 0 {
+		t.Errorf("Expected empty library, got %d snippets", len(lib.Snippets))
+	}
+}
+
+func TestPutSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	lib, _ := Load(dir)
+
+	if err := lib.Put(Snippet{Name: "disk-hogs", Command: "du -sh {{path}}/* | sort -rh | head", Description: "biggest directories"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if err := lib.Put(Snippet{Name: "ports", Command: "ss -tlnp"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	// Same name replaces in place
+	if err := lib.Put(Snippet{Name: "disk-hogs", Command: "du -sh {{path}}/* | sort -rh | head -n {{count}}"}); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if err := lib.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("Expected snippets file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "snippets:\n") {
+		t.Errorf("Expected a snippets list, got:\n%s", data)
+	}
+
+	lib, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(lib.Snippets) != 2 || lib.Snippets[0].Name != "disk-hogs" || lib.Snippets[1].Name != "ports" {
+		t.Fatalf("Expected disk-hogs then ports, got %+v", lib.Snippets)
+	}
+
+	s, err := lib.Get("disk-hogs")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if !strings.Contains(s.Command, "{{count}}") {
+		t.Errorf("Expected the replaced command, got '%s'", s.Command)
+	}
+
+	if _, err := lib.Get("missing"); err == nil {
+		t.Error("Expected error for unknown snippet")
+	}
+}
+
+func TestPut_Invalid(t *testing.T) {
+	lib, _ := Load(t.TempDir())
+
+	for _, s := range []Snippet{
+		{Name: "", Command: "ls"},
+		{Name: "has space", Command: "ls"},
+		{Name: "-flag", Command: "ls"},
+		{Name: "ok", Command: "  "},
+	} {
+		if err := lib.Put(s); err == nil {
+			t.Errorf("Expected error for %+v", s)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, FileName), []byte("snippets: [unclosed"), 0644)
+
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected parse error")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	lib := &Library{Snippets: []Snippet{
+		{Name: "disk-hogs", Command: "du -sh {{path}}/* | sort -rh | head", Description: "biggest directories by disk usage"},
+		{Name: "ports", Command: "ss -tlnp", Description: "listening ports"},
+		{Name: "git-prune", Command: "git fetch --prune"},
+	}}
+
+	matches := lib.Match("show disk usage in /var")
+	if len(matches) != 1 || matches[0].Name != "disk-hogs" {
+		t.Errorf("Expected disk-hogs, got %+v", matches)
+	}
+
+	matches = lib.Match("which ports are listening")
+	if len(matches) != 1 || matches[0].Name != "ports" {
+		t.Errorf("Expected ports, got %+v", matches)
+	}
+
+	if matches := lib.Match("show me the files"); len(matches) != 0 {
+		t.Errorf("Expected stop words not to match, got %+v", matches)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	names := Placeholders("find {{ path }} -name '{{pattern}}' -newer {{path}}")
+	if !reflect.DeepEqual(names, []string{"path", "pattern"}) {
+		t.Errorf("Expected [path pattern], got %v", names)
+	}
+}
+
+func TestFill(t *testing.T) {
+	command, missing := Fill("find {{ path }} -name '{{pattern}}' -newer {{path}}", map[string]string{"path": "/var"})
+
+	if command != "find /var -name '{{pattern}}' -newer /var" {
+		t.Errorf("Unexpected command: %s", command)
+	}
+	if !reflect.DeepEqual(missing, []string{"pattern"}) {
+		t.Errorf("Expected [pattern] missing, got %v", missing)
+	}
+}
+
+func TestParameterize(t *testing.T) {
+	command, err := Parameterize("du -sh /var/log/* /var/tmp | head -n 5", map[string]string{"logs": "/var/log", "count": "5"})
+	if err != nil {
+		t.Fatalf("Parameterize() failed: %v", err)
+	}
+	if command != "du -sh {{logs}}/* /var/tmp | head -n {{count}}" {
+		t.Errorf("Unexpected command: %s", command)
+	}
+
+	if _, err := Parameterize("ls /tmp", map[string]string{"path": "/var"}); err == nil {
+		t.Error("Expected error when the value is not in the command")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	values, rest := ParseArgs([]string{"path=/var", "only", "the", "top=3", "a=b=c", "=x"})
+
+	if !reflect.DeepEqual(values, map[string]string{"path": "/var", "top": "3", "a": "b=c"}) {
+		t.Errorf("Unexpected values: %v", values)
+	}
+	if !reflect.DeepEqual(rest, []string{"only", "the", "=x"}) {
+		t.Errorf("Unexpected rest: %v", rest)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, expected := range map[string]bool{"disk-hogs": true, "k8s.pods_2": true, "": false, "-x": false, "a b": false, "a/b": false} {
+		if ValidName(name) != expected {
+			t.Errorf("ValidName(%q) = %v, expected %v", name, !expected, expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ui
+
+import "strings"
+
+// WordDiff renders the changes from a to b in git's plain word-diff style:
+// removed words as [-word-] and added words as {+word+}
+func WordDiff(a, b string) string {
+	from, to := strings.Fields(a), strings.Fields(b)
+
+	// Longest common subsequence table over words
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var parts, removed, added []string
+	flush := func() {
+		if len(removed) > 0 {
+			parts = append(parts, "[-"+strings.Join(removed, " ")+"-]")
+		}
+		if len(added) > 0 {
+			parts = append(parts, "{+"+strings.Join(added, " ")+"+}")
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			flush()
+			parts = append(parts, from[i])
+			i++
+			j++
+		case j == len(to) || (i < len(from) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, from[i])
+			i++
+		default:
+			added = append(added, to[j])
+			j++
+		}
+	}
+	flush()
+
+	return strings.Join(parts, " ")
+}
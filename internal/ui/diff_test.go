@@ -0,0 +1,20 @@
+package ui
+
+import "testing"
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		a, b, expected string
+	}{
+		{"ls -la", "ls -la", "ls -la"},
+		{"wc -l data.cvs", "wc -l data.csv", "wc -l [-data.cvs-] {+data.csv+}"},
+		{"grep foo *.log", "grep -r foo .", "grep {+-r+} foo [-*.log-] {+.+}"},
+		{"", "echo hi", "{+echo hi+}"},
+	}
+
+	for _, tt := range tests {
+		if got := WordDiff(tt.a, tt.b); got != tt.expected {
+			t.Errorf("WordDiff(%q, %q): expected %q, got %q", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
@@ -3,29 +3,190 @@ package ui
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
+
+	sysContext "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/history"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/redact"
+	"github.com/palaforcade/zchat/internal/snippet"
 )
 
-type Display struct {
+// Display is how zchat talks to the user. TextDisplay writes for people; the JSON output
+// builds on it and also implements Reporter.
+type Display interface {
+	ShowCommand(command string, risk executor.Risk)
+	ShowCommandDiff(original, command string, risk executor.Risk)
+	ShowCached(age time.Duration)
+	ShowRedactions(findings []redact.Finding)
+	ShowContext(sysCtx *sysContext.SystemContext)
+	ShowExplanation(exp *llm.Explanation)
+	ShowResult(output *executor.Output, err error, duration time.Duration)
+	ShowSuccess(output string)
+	ShowError(err error)
+	ShowCancelled()
+	ShowWaiting(label string) (stop func())
+	SetHighlighter(h *Highlighter)
+
+	ShowPlan(plan *llm.Plan, warnings []string)
+	ShowPlanStep(n, total int, step llm.PlanStep)
+	ShowHistory(entries []history.Entry)
+	ShowSnippets(snippets []snippet.Snippet)
+
+	Prompter
+}
+
+// Prompter asks the user for answers
+type Prompter interface {
+	HasTTY() bool
+	ConfirmExecution() (bool, error)
+	ShowDangerWarning(reason string) (bool, error)
+	OfferFix() (bool, error)
+	PromptValue(name string) (string, error)
+	ConfirmPlan() (PlanMode, error)
+	PromptScriptPath() (string, error)
+	NewLineEditor(historyPath string) *LineEditor
+}
+
+// Reporter is a display that collects the run into a report for tools, written by Flush
+// when the run ends
+type Reporter interface {
+	ShowRequest(query, provider, model string)
+	Flush() error
+}
+
+var _ Display = (*TextDisplay)(nil)
+
+// TextDisplay writes plain text for a person at a terminal
+type TextDisplay struct {
 	reader *bufio.Reader
+	input  *os.File  // where answers to prompts are read from
+	tty    bool      // whether input is a terminal the user can answer on
+	out    io.Writer // where output goes; nil means stdout
+
+	highlight *Highlighter // colors commands; nil prints them plain
+	noSpinner bool         // never show a spinner while waiting for the model
+}
+
+// NewDisplay creates a new text display that reads answers from stdin, or from /dev/tty when
+// stdin is not a terminal (e.g. `cat log | zchat ...`), so prompts still reach the user
+func NewDisplay() *TextDisplay {
+	input, tty := os.Stdin, IsTerminal(int(os.Stdin.Fd()))
+	if !tty {
+		if f, err := os.Open("/dev/tty"); err == nil {
+			input, tty = f, true
+		}
+	}
+
+	return &TextDisplay{
+		reader: bufio.NewReader(input),
+		input:  input,
+		tty:    tty,
+	}
 }
 
-// NewDisplay creates a new display with stdin reader
-func NewDisplay() *Display {
-	return &Display{
-		reader: bufio.NewReader(os.Stdin),
+// output returns where the display writes, looked up on each call so tests can swap os.Stdout
+func (d *TextDisplay) output() io.Writer {
+	if d.out != nil {
+		return d.out
 	}
+	return os.Stdout
+}
+
+// HasTTY reports whether there is a terminal to ask the user on
+func (d *TextDisplay) HasTTY() bool {
+	return d.tty
+}
+
+// SetOutput sends what the display writes to w instead of stdout
+func (d *TextDisplay) SetOutput(w io.Writer) {
+	d.out = w
+}
+
+// SetHighlighter turns on colored commands; nil prints them plain
+func (d *TextDisplay) SetHighlighter(h *Highlighter) {
+	d.highlight = h
+}
+
+// ShowCommand prints the generated command, with its risk badge if colors are on
+func (d *TextDisplay) ShowCommand(command string, risk executor.Risk) {
+	fmt.Fprintf(d.output(), "Command: %s\n", d.render(command, risk))
+}
+
+// render returns command as it should be shown, highlighted if colors are on
+func (d *TextDisplay) render(command string, risk executor.Risk) string {
+	if d.highlight == nil {
+		return command
+	}
+	return d.highlight.Render(command, risk)
+}
+
+// DisableSpinner keeps the display from drawing a spinner, for callers that need the
+// terminal left alone, like the shell widgets
+func (d *TextDisplay) DisableSpinner() {
+	d.noSpinner = true
+}
+
+// ShowWaiting shows a spinner with label on stderr until stop is called. It stays off when
+// stderr isn't a terminal.
+func (d *TextDisplay) ShowWaiting(label string) (stop func()) {
+	if d.noSpinner || !IsTerminal(int(os.Stderr.Fd())) {
+		return func() {}
+	}
+
+	s := NewSpinner(os.Stderr, label)
+	s.Start()
+	return s.Stop
 }
 
-// ShowCommand prints the generated command
-func (d *Display) ShowCommand(command string) {
-	fmt.Printf("Command: %s\n", command)
+// ShowCancelled tells the user the command was not run
+func (d *TextDisplay) ShowCancelled() {
+	fmt.Fprintln(d.output(), "Command execution cancelled.")
+}
+
+// ShowResult prints a command's output, with the error first if it failed
+func (d *TextDisplay) ShowResult(output *executor.Output, err error, duration time.Duration) {
+	if err == nil {
+		d.ShowSuccess(output.Combined)
+		return
+	}
+
+	d.ShowError(err)
+	// Still show output if there is any (e.g., error messages from the command)
+	if output.Combined != "" {
+		fmt.Fprintln(d.output(), output.Combined)
+	}
+}
+
+// ShowCommandDiff prints a corrected command along with what changed from the original
+func (d *TextDisplay) ShowCommandDiff(original, command string, risk executor.Risk) {
+	fmt.Fprintf(d.output(), "Original: %s\n", original)
+	fmt.Fprintf(d.output(), "Command: %s\n", d.render(command, risk))
+	fmt.Fprintf(d.output(), "Changes: %s\n", WordDiff(original, command))
+}
+
+// OfferFix asks whether to send a failed command back to the model for a correction
+func (d *TextDisplay) OfferFix() (bool, error) {
+	fmt.Fprint(d.output(), "Ask the model to fix it? [y/N]: ")
+
+	input, err := d.reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+
+	input = strings.TrimSpace(strings.ToLower(input))
+
+	// Default to no, since the user may already know what went wrong
+	return input == "y" || input == "yes", nil
 }
 
 // ConfirmExecution prompts the user to confirm execution
-func (d *Display) ConfirmExecution() (bool, error) {
-	fmt.Print("Execute? [Y/n]: ")
+func (d *TextDisplay) ConfirmExecution() (bool, error) {
+	fmt.Fprint(d.output(), "Execute? [Y/n]: ")
 
 	input, err := d.reader.ReadString('\n')
 	if err != nil {
@@ -43,20 +204,20 @@ func (d *Display) ConfirmExecution() (bool, error) {
 }
 
 // ShowError prints an error message to stderr
-func (d *Display) ShowError(err error) {
+func (d *TextDisplay) ShowError(err error) {
 	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 }
 
 // ShowSuccess prints command output
-func (d *Display) ShowSuccess(output string) {
-	fmt.Print(output)
+func (d *TextDisplay) ShowSuccess(output string) {
+	fmt.Fprint(d.output(), output)
 }
 
 // ShowDangerWarning shows a warning about dangerous commands and asks for explicit confirmation
-func (d *Display) ShowDangerWarning(reason string) (bool, error) {
-	fmt.Println("\n⚠️  WARNING: Dangerous command detected!")
-	fmt.Printf("Reason: %s\n", reason)
-	fmt.Print("Are you SURE you want to execute this? [yes/no]: ")
+func (d *TextDisplay) ShowDangerWarning(reason string) (bool, error) {
+	fmt.Fprintln(d.output(), "\n⚠️  WARNING: Dangerous command detected!")
+	fmt.Fprintf(d.output(), "Reason: %s\n", reason)
+	fmt.Fprint(d.output(), "Are you SURE you want to execute this? [yes/no]: ")
 
 	input, err := d.reader.ReadString('\n')
 	if err != nil {
@@ -68,3 +229,46 @@ func (d *Display) ShowDangerWarning(reason string) (bool, error) {
 	// Require full "yes" to proceed
 	return input == "yes", nil
 }
+
+// ShowCached notes that the answer came from the response cache rather than the model
+func (d *TextDisplay) ShowCached(age time.Duration) {
+	fmt.Fprintf(os.Stderr, "(cached answer from %s ago; use --no-cache to ask the model again)\n", age.Round(time.Second))
+}
+
+// ShowRedactions lists secrets that were masked before sending to the LLM, without revealing them
+func (d *TextDisplay) ShowRedactions(findings []redact.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Masked %d secret(s) before sending to the model:\n", len(findings))
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "  - %s: %s\n", f.Rule, hint(f.Value))
+	}
+}
+
+// hint shows just enough of a secret to recognize it
+func hint(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return fmt.Sprintf("%s…%s (%d chars)", value[:4], value[len(value)-2:], len(value))
+}
+
+// ShowContext prints the context that accompanies each request to the model
+func (d *TextDisplay) ShowContext(sysCtx *sysContext.SystemContext) {
+	fmt.Fprintf(d.output(), "Shell: %s (%s/%s)\n", sysCtx.Shell, sysCtx.OS, sysCtx.Arch)
+	if sysCtx.ContextOff {
+		fmt.Fprintln(d.output(), "Directory: (withheld by .zchatignore)")
+		return
+	}
+
+	fmt.Fprintf(d.output(), "Directory: %s\n", sysCtx.WorkingDir)
+	fmt.Fprintf(d.output(), "Files (%d): %s\n", len(sysCtx.Files), strings.Join(sysCtx.Files, ", "))
+	for _, section := range sysCtx.Sections {
+		fmt.Fprintf(d.output(), "%s [%s, %d chars]:\n", section.Title, section.Provider, section.Size())
+		for _, item := range section.Items {
+			fmt.Fprintf(d.output(), "  %s\n", item)
+		}
+	}
+}
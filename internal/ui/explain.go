@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/llm"
+)
+
+// ShowExplanation prints a breakdown of a command as plain text
+func (d *TextDisplay) ShowExplanation(exp *llm.Explanation) {
+	fmt.Fprintf(d.output(), "Command: %s\n", exp.Command)
+	if exp.Summary != "" {
+		fmt.Fprintf(d.output(), "Summary: %s\n", exp.Summary)
+	}
+
+	if len(exp.Stages) > 0 {
+		fmt.Fprintln(d.output(), "\nStages:")
+		for i, stage := range exp.Stages {
+			fmt.Fprintf(d.output(), "  %d. %s\n", i+1, stage.Command)
+			if stage.Description != "" {
+				fmt.Fprintf(d.output(), "     %s\n", stage.Description)
+			}
+
+			width := 0
+			for _, flag := range stage.Flags {
+				width = max(width, len(flag.Flag))
+			}
+			for _, flag := range stage.Flags {
+				fmt.Fprintf(d.output(), "       %-*s  %s\n", width, flag.Flag, flag.Meaning)
+			}
+		}
+	}
+
+	fmt.Fprintln(d.output())
+	fmt.Fprintf(d.output(), "Reads:   %s\n", listOrNone(exp.Reads))
+	fmt.Fprintf(d.output(), "Writes:  %s\n", listOrNone(exp.Writes))
+	fmt.Fprintf(d.output(), "Network: %s\n", listOrNone(exp.Network))
+
+	level := exp.Risk.Level
+	if level == "" {
+		level = "unknown"
+	}
+	fmt.Fprintf(d.output(), "Risk:    %s\n", strings.ToUpper(level))
+	for _, reason := range exp.Risk.Reasons {
+		fmt.Fprintf(d.output(), "  - %s\n", reason)
+	}
+}
+
+// ShowExplanationJSON prints a breakdown of a command as a JSON object
+func (d *TextDisplay) ShowExplanationJSON(exp *llm.Explanation) error {
+	encoder := json.NewEncoder(d.output())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exp)
+}
+
+// listOrNone joins items for display, or says "none"
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/llm"
+)
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	fn()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+var testExplanation = &llm.Explanation{
+	Command: "find . -name '*.tmp' -delete",
+	Summary: "Deletes temporary files below the current directory",
+	Stages: []llm.Stage{{
+		Command:     "find . -name '*.tmp' -delete",
+		Description: "Finds and deletes .tmp files",
+		Flags: []llm.Flag{
+			{Flag: "-name '*.tmp'", Meaning: "match names ending in .tmp"},
+			{Flag: "-delete", Meaning: "delete each match"},
+		},
+	}},
+	Reads:  []string{"."},
+	Writes: []string{"*.tmp (deleted)"},
+	Risk:   executor.Risk{Level: executor.RiskMedium, Reasons: []string{"deletes files without confirmation"}},
+}
+
+func TestShowExplanation(t *testing.T) {
+	output := captureStdout(t, func() {
+		NewDisplay().ShowExplanation(testExplanation)
+	})
+
+	for _, want := range []string{
+		"Command: find . -name '*.tmp' -delete",
+		"  1. find . -name '*.tmp' -delete",
+		"       -name '*.tmp'  match names ending in .tmp",
+		"       -delete        delete each match",
+		"Writes:  *.tmp (deleted)",
+		"Network: none",
+		"Risk:    MEDIUM",
+		"  - deletes files without confirmation",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestShowExplanationJSON(t *testing.T) {
+	output := captureStdout(t, func() {
+		if err := NewDisplay().ShowExplanationJSON(testExplanation); err != nil {
+			t.Errorf("ShowExplanationJSON failed: %v", err)
+		}
+	})
+
+	var decoded llm.Explanation
+	if err := json.Unmarshal([]byte(output), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, output)
+	}
+	if decoded.Risk.Level != executor.RiskMedium || len(decoded.Stages[0].Flags) != 2 {
+		t.Errorf("Unexpected decoded explanation: %+v", decoded)
+	}
+}
@@ -0,0 +1,307 @@
+package ui
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/executor"
+)
+
+// tokenKind classifies a piece of a shell command for highlighting
+type tokenKind int
+
+const (
+	tokenSpace    tokenKind = iota
+	tokenWord               // an argument
+	tokenProgram            // the program a simple command runs
+	tokenFlag               // an argument starting with -
+	tokenString             // a quoted argument
+	tokenRedirect           // >, 2>&1, <<< and friends
+	tokenOperator           // |, ||, &&, ;, & and parentheses
+	tokenComment            // # to the end of the line
+)
+
+// token is a piece of a command; concatenating the tokens gives back the command exactly
+type token struct {
+	kind  tokenKind
+	text  string
+	start int // byte offset in the command
+}
+
+// Highlighting styles, as ANSI SGR parameters
+var tokenStyles = map[tokenKind]string{
+	tokenProgram:  "1;32",
+	tokenFlag:     "36",
+	tokenString:   "33",
+	tokenRedirect: "35",
+	tokenOperator: "1;35",
+	tokenComment:  "2",
+}
+
+// dangerStyle marks the parts of a command that match a dangerous pattern
+const dangerStyle = "1;4;31"
+
+// badgeStyles color the risk badge shown after a command
+var badgeStyles = map[string]string{
+	executor.RiskLow:    "30;42",
+	executor.RiskMedium: "30;43",
+	executor.RiskHigh:   "1;97;41",
+}
+
+// redirectPattern matches a redirection operator, with its file descriptors but not its target
+var redirectPattern = regexp.MustCompile(`^(?:&>>?|[0-9]*(?:>>|>\||<<<|<<|<>|>|<)(?:&(?:[0-9]+|-))?)`)
+
+// assignmentPattern matches a VAR=value prefix, after which the program is still to come
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// wrapperPrograms run the command that follows them, so the next word is a program too.
+// Each maps to its short options that take an argument, which is not the program.
+var wrapperPrograms = map[string]string{
+	"sudo": "CDghprTtUu", "doas": "Cu", "env": "CSu", "time": "fo", "nice": "n", "nohup": "", "exec": "a", "command": "",
+	"xargs": "adEILnPs",
+}
+
+// Highlighter renders commands with ANSI colors: programs, flags, strings, redirections and
+// operators each get a color, parts matching a dangerous pattern are flagged, and a badge
+// gives the command's risk level
+type Highlighter struct {
+	patterns []string
+}
+
+// NewHighlighter creates a highlighter that flags the given dangerous patterns
+func NewHighlighter(patterns []string) *Highlighter {
+	return &Highlighter{patterns: patterns}
+}
+
+// Render returns command colored for a terminal, followed by the badge for risk. The risk is
+// passed in rather than assessed here, since the checks may have seen the command with the
+// user's aliases expanded.
+func (h *Highlighter) Render(command string, risk executor.Risk) string {
+	danger := dangerRanges(command, h.patterns)
+
+	// Neighboring tokens with the same style are painted together, so a flagged
+	// "| sh" is one underlined run rather than three
+	var b strings.Builder
+	var run strings.Builder
+	runStyle := ""
+	for _, tok := range tokenize(command) {
+		style := tokenStyles[tok.kind]
+		if overlaps(danger, tok.start, tok.start+len(tok.text)) {
+			style = dangerStyle
+		}
+		if style != runStyle {
+			b.WriteString(paint(run.String(), runStyle))
+			run.Reset()
+			runStyle = style
+		}
+		run.WriteString(tok.text)
+	}
+	b.WriteString(paint(run.String(), runStyle))
+
+	b.WriteString("  ")
+	b.WriteString(paint(" "+risk.Level+" ", badgeStyles[risk.Level]))
+	return b.String()
+}
+
+// paint wraps text in an SGR sequence; an empty style leaves it plain
+func paint(text, style string) string {
+	if style == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + style + "m" + text + "\x1b[0m"
+}
+
+// SupportsColor reports whether output to fd should be colored when the color setting is
+// auto: fd must be a terminal, NO_COLOR must be unset and TERM must not be dumb
+func SupportsColor(fd int) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(fd)
+}
+
+// dangerRanges returns the byte ranges of command matched by the patterns, case-insensitively
+// like executor.IsDangerous
+func dangerRanges(command string, patterns []string) [][2]int {
+	lower := asciiLower(command)
+
+	var ranges [][2]int
+	for _, pattern := range patterns {
+		pattern = asciiLower(pattern)
+		if pattern == "" {
+			continue
+		}
+		for offset := 0; ; {
+			i := strings.Index(lower[offset:], pattern)
+			if i < 0 {
+				break
+			}
+			ranges = append(ranges, [2]int{offset + i, offset + i + len(pattern)})
+			offset += i + 1
+		}
+	}
+	return ranges
+}
+
+// asciiLower lowercases ASCII letters only, so byte offsets stay valid
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+// overlaps reports whether [start, end) intersects any of the ranges
+func overlaps(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits a command into tokens for highlighting. It only needs to be good enough to
+// color a command, so anything it doesn't understand becomes a plain word.
+func tokenize(command string) []token {
+	var tokens []token
+	expectProgram := true // the next word starts a simple command
+	wrapper := ""         // the last program runs another one, e.g. sudo
+	optionArg := false    // the next word is the argument of a wrapper's option
+	redirectTarget := false
+
+	add := func(kind tokenKind, start, end int) {
+		tokens = append(tokens, token{kind: kind, text: command[start:end], start: start})
+	}
+
+	for i := 0; i < len(command); {
+		start := i
+		c := command[i]
+
+		switch {
+		case c == ' ' || c == '\t':
+			for i < len(command) && (command[i] == ' ' || command[i] == '\t') {
+				i++
+			}
+			add(tokenSpace, start, i)
+		case c == '#':
+			for i < len(command) && command[i] != '\n' {
+				i++
+			}
+			add(tokenComment, start, i)
+		case redirectPattern.MatchString(command[i:]):
+			i += len(redirectPattern.FindString(command[i:]))
+			add(tokenRedirect, start, i)
+			redirectTarget = !strings.Contains(command[start:i], "&") || strings.HasPrefix(command[start:i], "&>")
+		case strings.ContainsRune("|&;\n()", rune(c)):
+			i++
+			if i < len(command) && (c == '|' && (command[i] == '|' || command[i] == '&') || c == '&' && command[i] == '&' || c == ';' && command[i] == ';') {
+				i++
+			}
+			add(tokenOperator, start, i)
+			expectProgram = c != ')'
+			wrapper = ""
+			optionArg = false
+		default:
+			i = wordEnd(command, i)
+			word := command[start:i]
+			switch {
+			case redirectTarget:
+				add(tokenWord, start, i)
+				redirectTarget = false
+			case optionArg:
+				add(tokenWord, start, i)
+				optionArg = false
+			case expectProgram && assignmentPattern.MatchString(word):
+				add(tokenWord, start, i)
+			case wrapper != "" && strings.HasPrefix(word, "-"):
+				add(tokenFlag, start, i)
+				optionArg = takesArgument(wrapperPrograms[wrapper], word)
+			case expectProgram:
+				add(tokenProgram, start, i)
+				_, expectProgram = wrapperPrograms[word]
+				wrapper = ""
+				if expectProgram {
+					wrapper = word
+				}
+			case word[0] == '\'' || word[0] == '"' || strings.HasPrefix(word, "$'"):
+				add(tokenString, start, i)
+			case len(word) > 1 && word[0] == '-':
+				add(tokenFlag, start, i)
+			default:
+				add(tokenWord, start, i)
+			}
+		}
+	}
+	return tokens
+}
+
+// takesArgument reports whether flag ends with one of options, leaving its argument to the
+// next word as in -u root; in -uroot the argument is part of the flag
+func takesArgument(options, flag string) bool {
+	if strings.HasPrefix(flag, "--") {
+		return false
+	}
+	for j := 1; j < len(flag); j++ {
+		if strings.IndexByte(options, flag[j]) >= 0 {
+			return j == len(flag)-1
+		}
+	}
+	return false
+}
+
+// wordEnd returns the end of the word starting at i, skipping over quotes, escapes and
+// $(...) or `...` substitutions
+func wordEnd(command string, i int) int {
+	for i < len(command) {
+		c := command[i]
+		switch {
+		case c == '\\':
+			i += 2
+		case c == '\'':
+			if end := strings.IndexByte(command[i+1:], '\''); end >= 0 {
+				i += end + 2
+			} else {
+				i = len(command)
+			}
+		case c == '"':
+			i++
+			for i < len(command) && command[i] != '"' {
+				if command[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == '`':
+			if end := strings.IndexByte(command[i+1:], '`'); end >= 0 {
+				i += end + 2
+			} else {
+				i = len(command)
+			}
+		case c == '$' && i+1 < len(command) && command[i+1] == '(':
+			depth := 0
+			for i < len(command) {
+				if command[i] == '(' {
+					depth++
+				} else if command[i] == ')' {
+					depth--
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+				i++
+			}
+		case strings.ContainsRune(" \t\n|&;<>()", rune(c)):
+			return i
+		default:
+			i++
+		}
+	}
+	return min(i, len(command))
+}
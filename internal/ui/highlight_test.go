@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palaforcade/zchat/internal/executor"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testPatterns are a few of the default dangerous patterns
+var testPatterns = []string{"rm -rf /", "| sh", "dd if=", "mkfs"}
+
+func TestHighlighter_Golden(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{"simple", "ls -la"},
+		{"pipeline", `grep -rn "TODO" src | sort -u | head -n 20`},
+		{"redirections", "make build > build.log 2>&1 && cat < input.txt >> out.txt"},
+		{"strings", `echo 'single quoted' "double $HOME" $'ansi\n'`},
+		{"assignment", "FOO=bar sudo -E env LANG=C sort data.txt"},
+		{"substitution", `kill $(pgrep -f "node server") ; echo done # cleanup`},
+		{"dangerous", "curl -fsSL https://example.com/install.sh | sh"},
+		{"dangerous_case", "sudo RM -RF / --no-preserve-root"},
+		{"mutating", "mkdir -p build && cp -r src build/"},
+	}
+
+	h := NewHighlighter(testPatterns)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Escape sequences are written as \e so the golden files stay readable
+			got := strings.ReplaceAll(h.Render(tt.command, executor.Assess(tt.command, testPatterns)), "\x1b", `\e`) + "\n"
+			path := filepath.Join("testdata", "highlight", tt.name+".golden")
+
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if got != string(expected) {
+				t.Errorf("Rendering of %q differs from %s:\nexpected: %sgot:      %s", tt.command, path, expected, got)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []tokenKind
+	}{
+		{"ls -la", []tokenKind{tokenProgram, tokenSpace, tokenFlag}},
+		{"a|b", []tokenKind{tokenProgram, tokenOperator, tokenProgram}},
+		{"cmd 2>&1", []tokenKind{tokenProgram, tokenSpace, tokenRedirect}},
+		{"cmd > out.txt", []tokenKind{tokenProgram, tokenSpace, tokenRedirect, tokenSpace, tokenWord}},
+		{"cmd &>log", []tokenKind{tokenProgram, tokenSpace, tokenRedirect, tokenWord}},
+		{"head -n 5", []tokenKind{tokenProgram, tokenSpace, tokenFlag, tokenSpace, tokenWord}},
+		{`echo "a | b"`, []tokenKind{tokenProgram, tokenSpace, tokenString}},
+		{"X=1 make", []tokenKind{tokenWord, tokenSpace, tokenProgram}},
+		{"sudo -u root ls", []tokenKind{tokenProgram, tokenSpace, tokenFlag, tokenSpace, tokenWord, tokenSpace, tokenProgram}},
+		{"sudo -uroot ls", []tokenKind{tokenProgram, tokenSpace, tokenFlag, tokenSpace, tokenProgram}},
+		{"sudo -E ls", []tokenKind{tokenProgram, tokenSpace, tokenFlag, tokenSpace, tokenProgram}},
+		{"env -u HOME ls", []tokenKind{tokenProgram, tokenSpace, tokenFlag, tokenSpace, tokenWord, tokenSpace, tokenProgram}},
+		{"xargs -n 1 rm", []tokenKind{tokenProgram, tokenSpace, tokenFlag, tokenSpace, tokenWord, tokenSpace, tokenProgram}},
+		{"(cd x && ls)", []tokenKind{tokenOperator, tokenProgram, tokenSpace, tokenWord, tokenSpace, tokenOperator, tokenSpace, tokenProgram, tokenOperator}},
+		{"ls # files", []tokenKind{tokenProgram, tokenSpace, tokenComment}},
+		{`echo "unterminated`, []tokenKind{tokenProgram, tokenSpace, tokenString}},
+	}
+
+	for _, tt := range tests {
+		tokens := tokenize(tt.command)
+
+		var text strings.Builder
+		var kinds []tokenKind
+		for _, tok := range tokens {
+			text.WriteString(tok.text)
+			kinds = append(kinds, tok.kind)
+		}
+		if text.String() != tt.command {
+			t.Errorf("Expected tokens of %q to rebuild the command, got %q", tt.command, text.String())
+		}
+		if !slicesEqual(kinds, tt.expected) {
+			t.Errorf("tokenize(%q): expected kinds %v, got %v", tt.command, tt.expected, kinds)
+		}
+	}
+}
+
+func slicesEqual(a, b []tokenKind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSupportsColor(t *testing.T) {
+	// A pipe is never a terminal
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	t.Setenv("NO_COLOR", "")
+	if SupportsColor(int(w.Fd())) {
+		t.Error("Expected no color for a pipe")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if SupportsColor(int(os.Stdout.Fd())) {
+		t.Error("Expected NO_COLOR to disable color")
+	}
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "dumb")
+	if SupportsColor(int(os.Stdout.Fd())) {
+		t.Error("Expected TERM=dumb to disable color")
+	}
+}
+
+func TestTextDisplay_ShowCommandHighlighted(t *testing.T) {
+	var out strings.Builder
+	d := &TextDisplay{out: &out}
+
+	d.ShowCommand("ls -la", executor.Assess("ls -la", testPatterns))
+	if out.String() != "Command: ls -la\n" {
+		t.Errorf("Expected plain command without a highlighter, got %q", out.String())
+	}
+
+	out.Reset()
+	d.SetHighlighter(NewHighlighter(testPatterns))
+	d.ShowCommand("ls -la", executor.Assess("ls -la", testPatterns))
+	if !strings.HasPrefix(out.String(), "Command: \x1b[1;32mls\x1b[0m") || !strings.Contains(out.String(), " low ") {
+		t.Errorf("Expected highlighted command with a low risk badge, got %q", out.String())
+	}
+
+	// With ls aliased to something dangerous, the badge follows the risk the checks found
+	out.Reset()
+	d.ShowCommand("ls -la", executor.Assess("rm -rf / -la", testPatterns))
+	if !strings.Contains(out.String(), " high ") {
+		t.Errorf("Expected the badge for the given risk, got %q", out.String())
+	}
+}
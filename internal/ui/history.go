@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/history"
+)
+
+// ShowHistory lists recorded interactions, oldest first, with the query under each command
+func (d *TextDisplay) ShowHistory(entries []history.Entry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(d.output(), "No history yet.")
+		return
+	}
+
+	for _, e := range entries {
+		status := "not run"
+		if e.Executed {
+			status = fmt.Sprintf("exit %d", e.ExitCode)
+		}
+		fmt.Fprintf(d.output(), "%5d  %s  %-7s  %s\n", e.ID, e.Time.Local().Format("2006-01-02 15:04"), status, e.Command)
+		if e.Query != "" {
+			fmt.Fprintf(d.output(), "%s# %s\n", strings.Repeat(" ", 34), e.Query)
+		}
+	}
+}
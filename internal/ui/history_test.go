@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/history"
+)
+
+func TestShowHistory(t *testing.T) {
+	when := time.Date(2026, 3, 4, 9, 30, 0, 0, time.Local)
+	entries := []history.Entry{
+		{ID: 7, Time: when, Query: "show disk hogs", Command: "du -sh * | sort -h", Executed: true},
+		{ID: 8, Time: when, Query: "delete tmp", Command: "rm -r tmp"},
+	}
+
+	output := captureStdout(t, func() {
+		NewDisplay().ShowHistory(entries)
+	})
+
+	expected := "    7  2026-03-04 09:30  exit 0   du -sh * | sort -h\n" +
+		"                                  # show disk hogs\n" +
+		"    8  2026-03-04 09:30  not run  rm -r tmp\n" +
+		"                                  # delete tmp\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+
+	output = captureStdout(t, func() {
+		NewDisplay().ShowHistory(nil)
+	})
+	if !strings.Contains(output, "No history yet.") {
+		t.Errorf("Expected empty message, got %q", output)
+	}
+}
@@ -0,0 +1,274 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxHistoryEntries bounds the persisted line history
+const maxHistoryEntries = 500
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl-C
+var ErrInterrupted = errors.New("interrupted")
+
+// LineEditor reads lines with emacs-style editing keys and persistent history
+type LineEditor struct {
+	reader      *bufio.Reader
+	out         io.Writer
+	fd          int
+	history     []string
+	historyPath string
+}
+
+// NewLineEditor creates a line editor on the display's input, keeping history in historyPath
+func (d *TextDisplay) NewLineEditor(historyPath string) *LineEditor {
+	// Session input comes from stdin even when prompts are answered on /dev/tty
+	reader := d.reader
+	if d.input != nil && d.input != os.Stdin {
+		reader = bufio.NewReader(os.Stdin)
+	}
+
+	e := &LineEditor{
+		reader:      reader,
+		out:         d.output(),
+		fd:          int(os.Stdin.Fd()),
+		historyPath: historyPath,
+	}
+	e.loadHistory()
+	return e
+}
+
+// ReadLine prompts for a line. It returns io.EOF on Ctrl-D at an empty line and ErrInterrupted on Ctrl-C.
+// Without a terminal, lines are read as-is.
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+		line, err := e.reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	line, err := e.edit(prompt)
+	restore()
+
+	if err == nil && strings.TrimSpace(line) != "" {
+		e.addHistory(line)
+	}
+	return line, err
+}
+
+// edit runs the editing loop on raw input
+func (e *LineEditor) edit(prompt string) (string, error) {
+	var buf []rune
+	pos := 0
+	histIdx := len(e.history)
+	pending := "" // line being typed before browsing history
+
+	setLine := func(line string) {
+		buf = []rune(line)
+		pos = len(buf)
+	}
+
+	e.refresh(prompt, buf, pos)
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(buf), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case 4: // Ctrl-D
+			if len(buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		case 1: // Ctrl-A
+			pos = 0
+		case 5: // Ctrl-E
+			pos = len(buf)
+		case 2: // Ctrl-B
+			pos = max(pos-1, 0)
+		case 6: // Ctrl-F
+			pos = min(pos+1, len(buf))
+		case 127, 8: // Backspace
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case 11: // Ctrl-K
+			buf = buf[:pos]
+		case 21: // Ctrl-U
+			buf = buf[pos:]
+			pos = 0
+		case 23: // Ctrl-W
+			start := pos
+			for start > 0 && unicode.IsSpace(buf[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(buf[start-1]) {
+				start--
+			}
+			buf = append(buf[:start], buf[pos:]...)
+			pos = start
+		case 16: // Ctrl-P
+			histIdx, pending = e.historyPrev(histIdx, pending, string(buf), setLine)
+		case 14: // Ctrl-N
+			histIdx = e.historyNext(histIdx, pending, setLine)
+		case 27: // Escape sequence
+			switch e.readEscape() {
+			case 'A':
+				histIdx, pending = e.historyPrev(histIdx, pending, string(buf), setLine)
+			case 'B':
+				histIdx = e.historyNext(histIdx, pending, setLine)
+			case 'C':
+				pos = min(pos+1, len(buf))
+			case 'D':
+				pos = max(pos-1, 0)
+			case 'H':
+				pos = 0
+			case 'F':
+				pos = len(buf)
+			case 'X': // Delete
+				if pos < len(buf) {
+					buf = append(buf[:pos], buf[pos+1:]...)
+				}
+			}
+		default:
+			if unicode.IsPrint(r) {
+				buf = append(buf[:pos], append([]rune{r}, buf[pos:]...)...)
+				pos++
+			}
+		}
+
+		e.refresh(prompt, buf, pos)
+	}
+}
+
+// readEscape decodes the rest of an escape sequence into a key: arrows as A-D, H/F for
+// home/end and X for delete. Unknown sequences return 0.
+func (e *LineEditor) readEscape() rune {
+	r, _, err := e.reader.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return 0
+	}
+
+	r, _, err = e.reader.ReadRune()
+	if err != nil {
+		return 0
+	}
+	if r < '0' || r > '9' {
+		return r
+	}
+
+	// Numbered keys like ESC [ 3 ~
+	num := r
+	for {
+		next, _, err := e.reader.ReadRune()
+		if err != nil || next == '~' {
+			break
+		}
+		if next < '0' || next > '9' {
+			return 0
+		}
+	}
+
+	switch num {
+	case '1', '7':
+		return 'H'
+	case '4', '8':
+		return 'F'
+	case '3':
+		return 'X'
+	default:
+		return 0
+	}
+}
+
+// historyPrev moves to the previous history entry, remembering the line being typed
+func (e *LineEditor) historyPrev(idx int, pending, current string, setLine func(string)) (int, string) {
+	if idx == 0 {
+		return idx, pending
+	}
+	if idx == len(e.history) {
+		pending = current
+	}
+	idx--
+	setLine(e.history[idx])
+	return idx, pending
+}
+
+// historyNext moves to the next history entry, or back to the line being typed
+func (e *LineEditor) historyNext(idx int, pending string, setLine func(string)) int {
+	if idx >= len(e.history) {
+		return idx
+	}
+	idx++
+	if idx == len(e.history) {
+		setLine(pending)
+	} else {
+		setLine(e.history[idx])
+	}
+	return idx
+}
+
+// refresh redraws the prompt and line and places the cursor
+func (e *LineEditor) refresh(prompt string, buf []rune, pos int) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(buf))
+	if back := len(buf) - pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+// addHistory records a line, skipping immediate repeats, and persists the history
+func (e *LineEditor) addHistory(line string) {
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistoryEntries {
+		e.history = e.history[len(e.history)-maxHistoryEntries:]
+	}
+
+	if e.historyPath == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(e.historyPath), 0700); err != nil {
+		return
+	}
+	// History is only a convenience, so write failures are ignored
+	os.WriteFile(e.historyPath, []byte(strings.Join(e.history, "\n")+"\n"), 0600)
+}
+
+// loadHistory reads persisted history, if any
+func (e *LineEditor) loadHistory() {
+	if e.historyPath == "" {
+		return
+	}
+	data, err := os.ReadFile(e.historyPath)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, history ...string) *LineEditor {
+	return &LineEditor{
+		reader:  bufio.NewReader(strings.NewReader(input)),
+		out:     &bytes.Buffer{},
+		fd:      -1,
+		history: history,
+	}
+}
+
+func TestLineEditor_Editing(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "list files\r", "list files"},
+		{"backspace", "lisst\x7f\x7ft\r", "list"},
+		{"insert after left arrow", "abc\x1b[D\x1b[DX\r", "aXbc"},
+		{"home and end", "bc\x1b[HA\x1b[FD\r", "AbcD"},
+		{"ctrl-a and ctrl-e", "bc\x01A\x05D\r", "AbcD"},
+		{"delete key", "abc\x01\x1b[3~\r", "bc"},
+		{"ctrl-w", "find large files\x17\r", "find large "},
+		{"ctrl-u", "abc def\x1b[D\x1b[D\x15\r", "ef"},
+		{"ctrl-k", "abc def\x01\x06\x0b\r", "a"},
+		{"unicode", "café\x7fe\r", "cafe"},
+	}
+
+	for _, tc := range testCases {
+		line, err := newTestEditor(tc.input).edit("> ")
+		if err != nil {
+			t.Errorf("%s: edit failed: %v", tc.name, err)
+		}
+		if line != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, line)
+		}
+	}
+}
+
+func TestLineEditor_History(t *testing.T) {
+	// Up twice, down once, then edit the recalled entry
+	e := newTestEditor("draft\x1b[A\x1b[A\x1b[B!\r", "first", "second")
+	line, _ := e.edit("> ")
+	if line != "second!" {
+		t.Errorf("Expected 'second!', got %q", line)
+	}
+
+	// Browsing back down restores the line being typed
+	e = newTestEditor("draft\x1b[A\x1b[B\r", "first")
+	line, _ = e.edit("> ")
+	if line != "draft" {
+		t.Errorf("Expected 'draft', got %q", line)
+	}
+}
+
+func TestLineEditor_ControlKeys(t *testing.T) {
+	if _, err := newTestEditor("abc\x03").edit("> "); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("Expected ErrInterrupted on Ctrl-C, got %v", err)
+	}
+	if _, err := newTestEditor("\x04").edit("> "); err != io.EOF {
+		t.Errorf("Expected EOF on Ctrl-D, got %v", err)
+	}
+	// Ctrl-D on a non-empty line deletes instead
+	if line, _ := newTestEditor("ab\x01\x04\r").edit("> "); line != "b" {
+		t.Errorf("Expected 'b', got %q", line)
+	}
+}
+
+func TestLineEditor_NoTerminal(t *testing.T) {
+	e := newTestEditor("show disk usage\nsecond\n")
+
+	line, err := e.ReadLine("> ")
+	if err != nil || line != "show disk usage" {
+		t.Errorf("Expected first line, got %q, %v", line, err)
+	}
+	line, _ = e.ReadLine("> ")
+	if line != "second" {
+		t.Errorf("Expected second line, got %q", line)
+	}
+	if _, err := e.ReadLine("> "); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
+
+func TestLineEditor_PersistHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "repl_history")
+
+	e := newTestEditor("")
+	e.historyPath = path
+	e.addHistory("one")
+	e.addHistory("one")
+	e.addHistory("two")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected history file: %v", err)
+	}
+	if string(data) != "one\ntwo\n" {
+		t.Errorf("Expected deduplicated history, got %q", data)
+	}
+
+	loaded := newTestEditor("")
+	loaded.historyPath = path
+	loaded.loadHistory()
+	if len(loaded.history) != 2 || loaded.history[1] != "two" {
+		t.Errorf("Expected history to reload, got %v", loaded.history)
+	}
+}
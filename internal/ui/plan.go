@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/llm"
+)
+
+// PlanMode is how the user chose to run a plan
+type PlanMode int
+
+const (
+	PlanCancel PlanMode = iota
+	PlanAll
+	PlanStepByStep
+)
+
+// ShowPlan lists the steps of a plan; warnings holds a danger reason per step, or ""
+func (d *TextDisplay) ShowPlan(plan *llm.Plan, warnings []string) {
+	fmt.Fprintf(d.output(), "Plan (%d steps):\n", len(plan.Steps))
+	for i, step := range plan.Steps {
+		fmt.Fprintf(d.output(), "  %d. %s\n", i+1, step.Description)
+		fmt.Fprintf(d.output(), "     %s\n", step.Command)
+		if i < len(warnings) && warnings[i] != "" {
+			fmt.Fprintf(d.output(), "     ⚠️  %s\n", warnings[i])
+		}
+	}
+}
+
+// ConfirmPlan asks whether to run every step, confirm each one, or cancel
+func (d *TextDisplay) ConfirmPlan() (PlanMode, error) {
+	fmt.Fprint(d.output(), "Run all steps, step by step, or cancel? [a/s/N]: ")
+
+	input, err := d.reader.ReadString('\n')
+	if err != nil {
+		return PlanCancel, err
+	}
+
+	switch strings.TrimSpace(strings.ToLower(input)) {
+	case "a", "all":
+		return PlanAll, nil
+	case "s", "step":
+		return PlanStepByStep, nil
+	default:
+		return PlanCancel, nil
+	}
+}
+
+// ShowPlanStep announces the step about to run
+func (d *TextDisplay) ShowPlanStep(n, total int, step llm.PlanStep) {
+	fmt.Fprintf(d.output(), "\n[%d/%d] %s\n", n, total, step.Description)
+	fmt.Fprintf(d.output(), "Command: %s\n", step.Command)
+}
+
+// PromptScriptPath asks where to save a completed plan; an empty answer skips saving
+func (d *TextDisplay) PromptScriptPath() (string, error) {
+	fmt.Fprint(d.output(), "Save plan as a script? Enter a path, or press Enter to skip: ")
+
+	input, err := d.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(input), nil
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/palaforcade/zchat/internal/llm"
+)
+
+func TestShowPlan(t *testing.T) {
+	plan := &llm.Plan{Steps: []llm.PlanStep{
+		{Description: "Clean build output", Command: "rm -rf build"},
+		{Description: "Build", Command: "make"},
+	}}
+
+	output := captureStdout(t, func() {
+		NewDisplay().ShowPlan(plan, []string{"Command contains dangerous pattern: rm -rf", ""})
+	})
+
+	expected := "Plan (2 steps):\n" +
+		"  1. Clean build output\n" +
+		"     rm -rf build\n" +
+		"     ⚠️  Command contains dangerous pattern: rm -rf\n" +
+		"  2. Build\n" +
+		"     make\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestConfirmPlan(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected PlanMode
+	}{
+		{"a\n", PlanAll},
+		{"ALL\n", PlanAll},
+		{"s\n", PlanStepByStep},
+		{"\n", PlanCancel},
+		{"n\n", PlanCancel},
+	}
+
+	for _, tc := range testCases {
+		display := &TextDisplay{reader: bufio.NewReader(strings.NewReader(tc.input))}
+
+		mode, err := display.ConfirmPlan()
+		if err != nil {
+			t.Errorf("ConfirmPlan() with input '%s' failed: %v", strings.TrimSpace(tc.input), err)
+		}
+		if mode != tc.expected {
+			t.Errorf("ConfirmPlan() with input '%s' = %v, expected %v", strings.TrimSpace(tc.input), mode, tc.expected)
+		}
+	}
+}
+
+func TestPromptScriptPath(t *testing.T) {
+	display := &TextDisplay{reader: bufio.NewReader(strings.NewReader("  setup.sh \n"))}
+
+	path, err := display.PromptScriptPath()
+	if err != nil || path != "setup.sh" {
+		t.Errorf("Expected setup.sh, got %q, %v", path, err)
+	}
+}
@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ask asks question and returns the answer, or defaultValue if the user just presses Enter
+func (d *TextDisplay) Ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(d.output(), "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(d.output(), "%s: ", question)
+	}
+
+	input, err := d.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if answer := strings.TrimSpace(input); answer != "" {
+		return answer, nil
+	}
+	return defaultValue, nil
+}
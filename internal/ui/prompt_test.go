@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestAsk(t *testing.T) {
+	var out strings.Builder
+	display := &TextDisplay{reader: bufio.NewReader(strings.NewReader("\n  anthropic \n")), out: &out}
+
+	answer, err := display.Ask("Provider", "ollama")
+	if err != nil || answer != "ollama" {
+		t.Errorf("Expected the default for an empty answer, got %q (%v)", answer, err)
+	}
+	answer, err = display.Ask("Provider", "ollama")
+	if err != nil || answer != "anthropic" {
+		t.Errorf("Expected 'anthropic', got %q (%v)", answer, err)
+	}
+	if !strings.Contains(out.String(), "Provider [ollama]: ") {
+		t.Errorf("Expected the default in the prompt, got %q", out.String())
+	}
+
+	if _, err := display.Ask("API key", ""); err == nil {
+		t.Error("Expected an error at end of input")
+	}
+}
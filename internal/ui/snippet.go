@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/palaforcade/zchat/internal/snippet"
+)
+
+// ShowSnippets lists saved snippets with their commands
+func (d *TextDisplay) ShowSnippets(snippets []snippet.Snippet) {
+	if len(snippets) == 0 {
+		fmt.Fprintln(d.output(), "No snippets saved yet. Save the last successful command with: zchat save <name>")
+		return
+	}
+
+	for _, s := range snippets {
+		fmt.Fprintf(d.output(), "%s: %s\n", s.Name, s.Command)
+		if s.Description != "" {
+			fmt.Fprintf(d.output(), "    # %s\n", s.Description)
+		}
+	}
+}
+
+// PromptValue asks the user for the value of a snippet placeholder
+func (d *TextDisplay) PromptValue(name string) (string, error) {
+	fmt.Fprintf(d.output(), "Value for {{%s}}: ", name)
+
+	input, err := d.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/palaforcade/zchat/internal/snippet"
+)
+
+func TestShowSnippets(t *testing.T) {
+	output := captureStdout(t, func() {
+		NewDisplay().ShowSnippets([]snippet.Snippet{
+			{Name: "disk-hogs", Command: "du -sh {{path}}/* | sort -rh | head", Description: "biggest directories"},
+			{Name: "ports", Command: "ss -tlnp"},
+		})
+	})
+
+	for _, want := range []string{"disk-hogs: du -sh {{path}}/* | sort -rh | head", "    # biggest directories", "ports: ss -tlnp"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	output = captureStdout(t, func() { NewDisplay().ShowSnippets(nil) })
+	if !strings.Contains(output, "No snippets saved yet") {
+		t.Errorf("Expected empty message, got '%s'", output)
+	}
+}
+
+func TestPromptValue(t *testing.T) {
+	display := &TextDisplay{reader: bufio.NewReader(strings.NewReader("  /var/log \n"))}
+
+	var value string
+	var err error
+	output := captureStdout(t, func() { value, err = display.PromptValue("path") })
+
+	if err != nil {
+		t.Fatalf("PromptValue() failed: %v", err)
+	}
+	if value != "/var/log" {
+		t.Errorf("Expected '/var/log', got '%s'", value)
+	}
+	if !strings.Contains(output, "Value for {{path}}: ") {
+		t.Errorf("Expected prompt, got '%s'", output)
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are drawn in turn while waiting
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// spinnerDelay keeps quick answers, like cache hits, from flashing a spinner
+	spinnerDelay = 150 * time.Millisecond
+	// spinnerInterval is how often the spinner is redrawn
+	spinnerInterval = 100 * time.Millisecond
+)
+
+// Spinner shows that zchat is waiting, with the elapsed time, on a line it redraws in place
+type Spinner struct {
+	out   io.Writer
+	label string
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewSpinner creates a spinner that writes to out, showing label
+func NewSpinner(out io.Writer, label string) *Spinner {
+	return &Spinner{
+		out:   out,
+		label: label,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start draws the spinner in the background until Stop is called
+func (s *Spinner) Start() {
+	go s.run()
+}
+
+func (s *Spinner) run() {
+	defer close(s.done)
+
+	start := time.Now()
+	select {
+	case <-s.stop:
+		return
+	case <-time.After(spinnerDelay):
+	}
+
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		// \r\x1b[K redraws from the start of a cleared line, which also wipes the ^C the
+		// terminal echoes when the user interrupts
+		fmt.Fprintf(s.out, "\r\x1b[K%s Asking %s... %.1fs (Ctrl-C to cancel)", spinnerFrames[frame%len(spinnerFrames)], s.label, time.Since(start).Seconds())
+		select {
+		case <-s.stop:
+			fmt.Fprint(s.out, "\r\x1b[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop erases the spinner and returns once its line is clear, so whatever is printed next
+// starts on a clean line. It is safe to call more than once.
+func (s *Spinner) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
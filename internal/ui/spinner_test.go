@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpinner(t *testing.T) {
+	var out strings.Builder
+	s := NewSpinner(&out, "ollama/qwen2.5-coder:7b")
+	s.Start()
+	time.Sleep(spinnerDelay + 2*spinnerInterval)
+	s.Stop()
+	s.Stop()
+
+	got := out.String()
+	if !strings.Contains(got, "Asking ollama/qwen2.5-coder:7b...") || !strings.Contains(got, "Ctrl-C to cancel") {
+		t.Errorf("Expected the spinner to show the provider and model, got %q", got)
+	}
+	if !strings.HasSuffix(got, "\r\x1b[K") {
+		t.Errorf("Expected the spinner to clear its line when stopped, got %q", got)
+	}
+}
+
+func TestSpinner_QuickAnswer(t *testing.T) {
+	var out strings.Builder
+	s := NewSpinner(&out, "ollama/qwen2.5-coder:7b")
+	s.Start()
+	s.Stop()
+
+	if out.String() != "" {
+		t.Errorf("Expected nothing drawn for an answer quicker than the delay, got %q", out.String())
+	}
+}
+
+func TestTextDisplay_ShowWaitingDisabled(t *testing.T) {
+	d := &TextDisplay{noSpinner: true}
+	stop := d.ShowWaiting("ollama/qwen2.5-coder:7b")
+	stop()
+}
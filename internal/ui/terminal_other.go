@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package ui
+
+import "errors"
+
+// IsTerminal reports whether fd refers to a terminal; terminal detection is unsupported on this platform
+func IsTerminal(fd int) bool {
+	return false
+}
+
+// makeRaw is unsupported on this platform, so line editing falls back to plain input
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
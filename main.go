@@ -2,105 +2,623 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/palaforcade/zchat/internal/cli"
 	"github.com/palaforcade/zchat/internal/config"
 	contextPkg "github.com/palaforcade/zchat/internal/context"
 	"github.com/palaforcade/zchat/internal/executor"
 	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/redact"
+	"github.com/palaforcade/zchat/internal/shellinit"
 	"github.com/palaforcade/zchat/internal/ui"
 )
 
 func main() {
 	// Parse arguments
-	if len(os.Args) < 2 {
-		showUsage()
+	inv, err := commandLine.Parse(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\nRun 'zchat --help' for usage.\n", err)
+		os.Exit(1)
+	}
+	opts, err := parseOptions(inv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	query := strings.Join(os.Args[1:], " ")
+	if opts.help {
+		showUsage(inv.Command)
+		return
+	}
+
+	args := inv.Args
+	if inv.Command != "" && runSubcommand(inv.Command, args, opts) {
+		return
+	}
+	// A lone "fix" corrects the last failed command; "fix the permissions on x" is still a query,
+	// and so is anything else that only starts like a command, such as "history of ..."
+	fixMode := inv.Command == "fix" && len(args) == 0
+	if inv.Command != "" && inv.Command != "ask" && !fixMode {
+		args = append([]string{inv.Command}, args...)
+	}
+	hasQuery := len(args) > 0 || fixMode
+
+	if inv.Command == "ask" && !hasQuery {
+		fmt.Fprintln(os.Stderr, "Usage: zchat ask [flags] [--] <natural language query>")
+		os.Exit(1)
+	}
+	if opts.print && (opts.interactive || opts.plan || !hasQuery) {
+		fmt.Fprintln(os.Stderr, "Usage: zchat --print [--] <natural language query>")
+		os.Exit(1)
+	}
+	if opts.output != "text" && opts.output != "json" && opts.output != "" {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format %q (use text or json)\n", opts.output)
+		os.Exit(1)
+	}
+	if opts.output == "json" && (opts.interactive || opts.plan || opts.print || !hasQuery) {
+		fmt.Fprintln(os.Stderr, "Usage: zchat --output json [--explain] [--] <natural language query>")
+		os.Exit(1)
+	}
+	if opts.interactive || !hasQuery {
+		runREPL(opts)
+		return
+	}
+	query := strings.Join(args, " ")
+
+	display := newDisplay(opts)
 
 	// Load config
-	cfg, err := config.Load()
+	cfg, err := loadConfig(opts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
+		fail(display, "loading config", err)
+	}
+	stateDir, _ := config.StateDir()
+
+	var failure *executor.Failure
+	if fixMode {
+		failure, err = executor.LoadFailure(stateDir)
+		if err != nil || failure == nil {
+			display.ShowError(errors.New("no failed command to fix"))
+			exit(display, 1)
+		}
+		query = failure.Query
 	}
 
 	// Collect context
-	collector := contextPkg.NewDefaultCollector(cfg.MaxContextLines)
+	collector, err := buildCollector(cfg)
+	if err != nil {
+		fail(display, "loading config", err)
+	}
 	sysCtx, err := collector.Collect()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error collecting context: %v\n", err)
-		os.Exit(1)
+		fail(display, "collecting context", err)
+	}
+
+	// Preview files named in the query, unless a .zchatignore withholds the context
+	var snippets *contextPkg.Section
+	if !sysCtx.ContextOff {
+		if cfg.FilePreviewBytes > 0 {
+			sampler := contextPkg.NewFileSampler(cfg.FilePreviewBytes)
+			sysCtx.Samples = sampler.Sample(query, sysCtx.WorkingDir)
+		}
+		snippets = snippetSection(query)
 	}
+	if snippets != nil {
+		sysCtx.Sections = append(sysCtx.Sections, *snippets)
+	}
+
+	// Mask secrets in everything sent to the LLM
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		fail(display, "loading config", err)
+	}
+	findings := sysCtx.Redact(redactor)
+	query, queryFindings := redactor.Redact(query)
+	findings = append(findings, queryFindings...)
 
-	// Generate command
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	if cfg.Verbose {
+		display.ShowRedactions(findings)
+	}
+	if reporter, ok := display.(ui.Reporter); ok {
+		reporter.ShowRequest(query, cfg.Provider, cfg.Model)
+	}
 
 	// Create LLM client based on provider
-	var llmClient llm.Client
+	llmClient, err := newClient(cfg)
+	if err != nil {
+		fail(display, "creating client", err)
+	}
+
+	rec := newRecorder(cfg, stateDir)
+
+	if opts.plan {
+		runPlan(cfg, display, llmClient, redactor, rec, query, sysCtx, stateDir)
+		return
+	}
+
+	// Generate command, or a correction of the last failed one
+	var command, original string
+	var edits []string // earlier versions replaced by fixes, for the history
+	rounds := 0
+	if failure != nil {
+		original = failure.Command
+		edits = append(edits, failure.Command)
+		command, err = fixCommand(cfg, llmClient, redactor, display, failure, sysCtx)
+		rounds++
+	} else {
+		command, err = generateCommand(cfg, display, llmClient, query, sysCtx)
+	}
+	if err != nil {
+		fail(display, "generating command", err)
+	}
+	showCacheHit(display, llmClient)
+	answer := cachedAnswer(llmClient)
+
+	// Print mode hands the command to the shell integration, which puts it on the command line
+	if opts.print {
+		rec.save(rec.newEntry(query, command, sysCtx))
+		fmt.Println(command)
+		return
+	}
+
+	if snippets != nil && cfg.Approve != config.ApproveNever {
+		// The model reused a snippet but couldn't fill every placeholder from the request
+		if command, err = promptPlaceholders(display, command); err != nil {
+			display.ShowCancelled()
+			exit(display, 0)
+		}
+	}
+
+	exec := newExecutor(cfg, sysCtx)
+	setColor(display, cfg)
+
+	for {
+		// Display command
+		if original != "" {
+			display.ShowCommandDiff(original, command, assessCommand(cfg, command))
+		} else {
+			display.ShowCommand(command, assessCommand(cfg, command))
+		}
+		if opts.explain {
+			showExplanation(cfg, display, llmClient, redactor, command)
+		}
+
+		entry := rec.newEntry(query, command, sysCtx)
+		entry.Edits = edits
+
+		if cfg.Approve == config.ApproveNever {
+			rec.save(entry)
+			exit(display, 0)
+		}
+
+		// Safety check and confirmation
+		if !confirmCommand(display, cfg, command) {
+			rec.save(entry)
+			exitNotRun(display, cfg)
+		}
+
+		// Execute
+		output, err := executeRecorded(cfg, exec, command, entry)
+		rec.save(entry)
+		display.ShowResult(output, err, time.Duration(entry.DurationMs)*time.Millisecond)
+		if err == nil {
+			if stateDir != "" {
+				executor.ClearFailure(stateDir)
+			}
+			exit(display, 0)
+		}
+		forgetCachedAnswer(llmClient, answer)
+
+		// Remember the failure for `zchat fix`, keeping secrets from the output off disk
+		failure = executor.NewFailure(query, command, output.Combined, err)
+		failure.Output = redactor.String(failure.Output)
+		if stateDir != "" {
+			executor.SaveFailure(stateDir, failure)
+		}
+
+		if rounds >= cfg.MaxFixRounds {
+			exit(display, 1)
+		}
+		if retry, err := display.OfferFix(); err != nil || !retry {
+			exit(display, 1)
+		}
+
+		original = command
+		edits = append(edits, command)
+		command, err = fixCommand(cfg, llmClient, redactor, display, failure, sysCtx)
+		rounds++
+		if err != nil {
+			fail(display, "generating command", err)
+		}
+		showCacheHit(display, llmClient)
+		answer = cachedAnswer(llmClient)
+	}
+}
+
+// newDisplay creates the display for the --output format
+func newDisplay(opts options) ui.Display {
+	if opts.output == "json" {
+		return newJSONDisplay()
+	}
+	display := ui.NewDisplay()
+	if opts.print {
+		// The shell widgets redraw their prompt afterwards; a spinner would garble it
+		display.DisableSpinner()
+	}
+	return display
+}
+
+// exit flushes a reporting display, so JSON mode writes its result, and exits with code
+func exit(display ui.Display, code int) {
+	if reporter, ok := display.(ui.Reporter); ok {
+		if err := reporter.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(code)
+}
+
+// fail reports a fatal error while doing what, and exits with status 1, or 130 like a shell
+// if the user interrupted a request
+func fail(display ui.Display, what string, err error) {
+	display.ShowError(fmt.Errorf("%s: %w", what, err))
+	if errors.Is(err, errCancelled) {
+		exit(display, 130)
+	}
+	exit(display, 1)
+}
+
+// runSubcommand runs the command with args and reports whether it was one. Commands that
+// could also start a request only run if their arguments fit, so "history of ..." is a query.
+func runSubcommand(command string, args []string, opts options) bool {
+	switch {
+	case command == "explain":
+		runExplain(args, opts)
+	case command == "history" && (len(args) == 0 || args[0] == "search" || args[0] == "rerun"):
+		textOnly(opts, "history")
+		runHistory(args, opts)
+	// "save <name> [param=value ...]" and "run <saved name> ..."; anything else is a query
+	case command == "save" && isSaveCommand(args):
+		textOnly(opts, "save")
+		runSave(args)
+	case command == "run" && (len(args) == 0 || hasSnippet(args[0])):
+		textOnly(opts, "run")
+		runSnippet(args, opts)
+	case command == "config" && (len(args) == 0 || slices.Contains(configCommands, args[0])):
+		textOnly(opts, "config")
+		runConfig(args, opts)
+	case command == "init" && len(args) == 1 && slices.Contains(shellinit.Shells, args[0]):
+		textOnly(opts, "init")
+		runInit(args)
+	case command == "completion" && len(args) == 1 && slices.Contains(cli.Shells, args[0]):
+		textOnly(opts, "completion")
+		runCompletion(args[0])
+	default:
+		return false
+	}
+	return true
+}
+
+// textOnly exits with an error if JSON output was asked for a subcommand that doesn't support it
+func textOnly(opts options, subcommand string) {
+	if opts.output == "json" {
+		fmt.Fprintf(os.Stderr, "Error: zchat %s does not support --output json\n", subcommand)
+		os.Exit(1)
+	}
+}
+
+// errCancelled reports a model request the user interrupted with Ctrl-C
+var errCancelled = errors.New("request cancelled")
+
+// startRequest prepares a model request: its context is bounded by the timeout setting and
+// cancelled by Ctrl-C, and a spinner shows the provider, model and elapsed time until done
+func startRequest(display ui.Display, cfg *config.Config) (ctx context.Context, done func()) {
+	ctx, cancel := withTimeout(cfg)
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt)
+	stopSpinner := display.ShowWaiting(cfg.Provider + "/" + cfg.Model)
+
+	return ctx, func() {
+		stopSpinner()
+		stopSignals()
+		cancel()
+	}
+}
+
+// withTimeout returns a context bounded by the timeout setting, unless it is 0
+func withTimeout(cfg *config.Config) (context.Context, context.CancelFunc) {
+	if cfg.Timeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), cfg.Timeout)
+}
+
+// requestError replaces the error of a request interrupted with Ctrl-C by errCancelled. It
+// must be called before the request's done, which cancels ctx too.
+func requestError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return errCancelled
+	}
+	return err
+}
+
+// generateCommand asks the model for a command
+func generateCommand(cfg *config.Config, display ui.Display, client llm.Client, query string, sysCtx *contextPkg.SystemContext) (string, error) {
+	ctx, done := startRequest(display, cfg)
+	defer done()
+
+	command, err := client.GenerateCommand(ctx, query, sysCtx)
+	return command, requestError(ctx, err)
+}
+
+// fixCommand sends a failed command, its exit status and output back to the model for a correction
+func fixCommand(cfg *config.Config, client llm.Client, redactor *redact.Redactor, display ui.Display, failure *executor.Failure, sysCtx *contextPkg.SystemContext) (string, error) {
+	fixQuery, findings := redactor.Redact(llm.BuildFixQuery(failure.Query, failure.Command, failure.ExitCode, failure.Output))
+	if cfg.Verbose {
+		display.ShowRedactions(findings)
+	}
+
+	return generateCommand(cfg, display, client, fixQuery, sysCtx)
+}
+
+// confirmCommand runs the dangerous-pattern check and applies the approval policy, asking the
+// user to confirm execution unless the policy approves the command. Under a policy, commands
+// that still need an answer are refused when there is no terminal to ask on.
+func confirmCommand(display ui.Display, cfg *config.Config, command string) bool {
+	if cfg.Approve == config.ApproveNever {
+		return false
+	}
+
+	checked, _ := checkedCommand(cfg, command)
+	if isDangerous, reason := executor.IsDangerous(checked, cfg.DangerousPatterns); isDangerous {
+		if !confirmDanger(display, cfg, reason) {
+			return false
+		}
+		if cfg.Approve == config.ApproveAll {
+			return true
+		}
+	}
+
+	if approvedByPolicy(cfg, command) {
+		return true
+	}
+	if !canAsk(display, cfg) {
+		fmt.Fprintln(os.Stderr, "Refusing to run without a terminal to confirm on: the command may change something (--approve=readonly only runs read-only commands)")
+		return false
+	}
+
+	confirmed, err := display.ConfirmExecution()
+	return err == nil && confirmed
+}
+
+// exitNotRun exits after a command was not run: with 1 if the approval policy refused it
+// with no one to ask, so scripts can tell, and with 0 if the user declined or --no-exec is set
+func exitNotRun(display ui.Display, cfg *config.Config) {
+	if cfg.Approve == config.ApproveNever {
+		exit(display, 0)
+	}
+	if !canAsk(display, cfg) {
+		exit(display, 1)
+	}
+	display.ShowCancelled()
+	exit(display, 0)
+}
+
+// confirmDanger asks for an explicit "yes" to a dangerous command, whatever the approval policy
+func confirmDanger(display ui.Display, cfg *config.Config, reason string) bool {
+	if !canAsk(display, cfg) {
+		fmt.Fprintf(os.Stderr, "Refusing to run without a terminal to confirm on: %s\n", reason)
+		return false
+	}
+	confirmed, err := display.ShowDangerWarning(reason)
+	return err == nil && confirmed
+}
+
+// approvedByPolicy reports whether the approval policy lets command run without asking
+func approvedByPolicy(cfg *config.Config, command string) bool {
+	checked, known := checkedCommand(cfg, command)
+	return cfg.Approve == config.ApproveAll || cfg.Approve == config.ApproveReadOnly && known && executor.IsReadOnly(checked)
+}
+
+// assessCommand returns the risk of command as the safety checks see it
+func assessCommand(cfg *config.Config, command string) executor.Risk {
+	checked, _ := checkedCommand(cfg, command)
+	return executor.Assess(checked, cfg.DangerousPatterns)
+}
+
+// checkedCommand returns command as the safety checks should see it. With exec_with_aliases
+// the user's aliases expand when it runs, so they are expanded here too; known is false if
+// they couldn't be read, in which case what runs isn't known.
+func checkedCommand(cfg *config.Config, command string) (checked string, known bool) {
+	if !cfg.ExecWithAliases {
+		return command, true
+	}
+	aliases, err := shellAliases(cfg)
+	if err != nil {
+		return command, false
+	}
+	return executor.ExpandAliases(command, aliases), true
+}
+
+var (
+	aliasesOnce sync.Once
+	aliases     map[string]string
+	aliasesErr  error
+)
+
+// shellAliases returns the aliases and function bodies commands run with, reading them once
+func shellAliases(cfg *config.Config) (map[string]string, error) {
+	aliasesOnce.Do(func() {
+		ctx := context.Background()
+		if timeout := cfg.ProviderLimits["aliases"].Timeout; timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		var defined, functions []contextPkg.ShellAlias
+		defined, functions, aliasesErr = contextPkg.NewAliasReader(contextPkg.DefaultCacheDir()).Read(ctx, contextPkg.DetectShell())
+		aliases = make(map[string]string, len(defined)+len(functions))
+		// An alias shadows a function of the same name
+		for _, alias := range append(functions, defined...) {
+			aliases[alias.Name] = alias.Definition
+		}
+	})
+	return aliases, aliasesErr
+}
+
+// canAsk reports whether the user may be asked. Without a policy, answers are read from stdin
+// as before even if it isn't a terminal; with one, only a terminal counts.
+func canAsk(display ui.Display, cfg *config.Config) bool {
+	return cfg.Approve == config.ApprovePrompt || cfg.Approve == "" || display.HasTTY()
+}
+
+// loadConfig loads the configuration and applies the command-line overrides
+func loadConfig(opts options) (*config.Config, error) {
+	cfg, _, err := loadConfigWithSources(opts)
+	return cfg, err
+}
+
+// loadConfigWithSources loads the configuration like loadConfig, and also reports where each
+// setting got its value
+func loadConfigWithSources(opts options) (*config.Config, config.Sources, error) {
+	cfg, sources, err := config.LoadWithSources(opts.profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, s := range opts.settings {
+		if err := cfg.Override(s.key, s.value, "flag "+s.flag, sources); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", s.flag, err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, sources, nil
+}
+
+// newClient creates the LLM client for the configured provider, behind the response cache if enabled
+func newClient(cfg *config.Config) (llm.Client, error) {
+	var client llm.Client
 	switch cfg.Provider {
 	case "anthropic":
-		llmClient = llm.NewAnthropicClient(cfg.APIKey, cfg.Model)
+		client = llm.NewAnthropicClient(cfg.APIKey, cfg.Model)
 	case "ollama":
-		llmClient = llm.NewOllamaClient(cfg.OllamaURL, cfg.Model)
+		client = llm.NewOllamaClient(cfg.OllamaURL, cfg.Model)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown provider: %s\n", cfg.Provider)
-		os.Exit(1)
+		return nil, fmt.Errorf("Unknown provider: %s", cfg.Provider)
 	}
 
-	command, err := llmClient.GenerateCommand(ctx, query, sysCtx)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating command: %v\n", err)
-		os.Exit(1)
+	cacheDir := contextPkg.DefaultCacheDir()
+	if !cfg.ResponseCache || cacheDir == "" {
+		return client, nil
 	}
+	return llm.NewCachingClient(client, filepath.Join(cacheDir, "responses"), cfg.Provider, cfg.Model, cfg.CacheTTL, cfg.CacheMaxEntries), nil
+}
 
-	// Display command
-	display := ui.NewDisplay()
-	display.ShowCommand(command)
+// setColor highlights commands if the color setting, and for auto the terminal, allow it
+func setColor(display ui.Display, cfg *config.Config) {
+	switch cfg.Color {
+	case config.ColorNever:
+		return
+	case config.ColorAuto, "":
+		if !ui.SupportsColor(int(os.Stdout.Fd())) {
+			return
+		}
+	}
+	display.SetHighlighter(ui.NewHighlighter(cfg.DangerousPatterns))
+}
 
-	// Safety check
-	if isDangerous, reason := executor.IsDangerous(command, cfg.DangerousPatterns); isDangerous {
-		confirmed, err := display.ShowDangerWarning(reason)
-		if err != nil || !confirmed {
-			fmt.Println("Command execution cancelled.")
-			os.Exit(0)
+// showCacheHit marks answers that were served from the response cache
+func showCacheHit(display ui.Display, client llm.Client) {
+	if cache, ok := client.(*llm.CachingClient); ok {
+		if hit, age := cache.LastHit(); hit {
+			display.ShowCached(age)
 		}
 	}
+}
 
-	// Confirm execution
-	confirmed, err := display.ConfirmExecution()
-	if err != nil || !confirmed {
-		fmt.Println("Command execution cancelled.")
-		os.Exit(0)
+// cachedAnswer returns the cache key of the answer just received, so that it can be forgotten
+// if its command fails; later requests, like an explanation, would otherwise be forgotten instead
+func cachedAnswer(client llm.Client) string {
+	if cache, ok := client.(*llm.CachingClient); ok {
+		return cache.LastKey()
 	}
+	return ""
+}
 
-	// Execute
+// forgetCachedAnswer drops a cached answer so that asking again reaches the model
+func forgetCachedAnswer(client llm.Client, key string) {
+	if cache, ok := client.(*llm.CachingClient); ok {
+		cache.Forget(key)
+	}
+}
+
+// newExecutor creates an executor for the detected shell with the configured safety patterns
+func newExecutor(cfg *config.Config, sysCtx *contextPkg.SystemContext) *executor.SafeExecutor {
 	exec := executor.NewSafeExecutor(cfg.DangerousPatterns, sysCtx.Shell)
-	output, err := exec.Execute(ctx, command)
-	if err != nil {
-		display.ShowError(err)
-		// Still show output if there is any (e.g., error messages from the command)
-		if output != "" {
-			fmt.Println(output)
+	if cfg.ExecWithAliases {
+		exec.EnableAliases()
+	}
+	return exec
+}
+
+// newRedactor creates a redactor with the built-in rules and the configured patterns
+func newRedactor(cfg *config.Config) (*redact.Redactor, error) {
+	redactor := redact.New()
+	for _, pattern := range cfg.RedactPatterns {
+		if err := redactor.AddPattern(pattern); err != nil {
+			return nil, err
 		}
-		os.Exit(1)
+	}
+	return redactor, nil
+}
+
+// buildCollector registers the configured context providers with their limits
+func buildCollector(cfg *config.Config) (*contextPkg.DefaultCollector, error) {
+	collector := contextPkg.NewProviderCollector(cfg.ContextBudget)
+	opts := contextPkg.ProviderOptions{
+		MaxFiles:         cfg.MaxContextLines,
+		HistoryEntries:   cfg.HistoryEntries,
+		HistoryMaxChars:  cfg.HistoryMaxChars,
+		AliasesAvailable: cfg.ExecWithAliases,
 	}
 
-	display.ShowSuccess(output)
+	for _, name := range cfg.EnabledProviders() {
+		provider, err := contextPkg.NewProvider(name, opts)
+		if err != nil {
+			return nil, err
+		}
+		limit := cfg.ProviderLimits[name]
+		collector.AddProvider(provider, contextPkg.ProviderLimit{Timeout: limit.Timeout, MaxChars: limit.MaxChars})
+	}
+
+	return collector, nil
 }
 
-func showUsage() {
-	fmt.Println("Usage: zchat <natural language query>")
+func showUsage(command string) {
+	if c := commandLine.Command(command); c != nil {
+		fmt.Print(commandLine.CommandUsage(c))
+		return
+	}
+	fmt.Print(commandLine.Usage())
+	fmt.Println()
+	fmt.Println("Every setting can also be given for one run as a flag, like --max-fix-rounds 1 or --shell-history, except the API key and profiles.")
 	fmt.Println()
 	fmt.Println("Example:")
 	fmt.Println("  zchat list the number of lines in analysis_data.csv")
 	fmt.Println("  zchat find all python files modified in the last week")
 	fmt.Println("  zchat show disk usage sorted by size")
+	fmt.Println("  zchat --plan create a venv, install requirements and run the tests")
 	fmt.Println()
 	fmt.Println("Configuration:")
 	fmt.Println("  Default provider: Ollama (local)")
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/config"
+	contextPkg "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/redact"
+)
+
+// failingClient fails every request like an unreachable server
+type failingClient struct {
+	err error
+}
+
+func (c *failingClient) GenerateCommand(ctx context.Context, query string, sysCtx *contextPkg.SystemContext) (string, error) {
+	return "", c.err
+}
+
+func (c *failingClient) ExplainCommand(ctx context.Context, command string, sysCtx *contextPkg.SystemContext) (*llm.Explanation, error) {
+	return nil, c.err
+}
+
+func (c *failingClient) GeneratePlan(ctx context.Context, query string, sysCtx *contextPkg.SystemContext) (*llm.Plan, error) {
+	return nil, c.err
+}
+
+// countingClient answers every request, counting the commands it generates
+type countingClient struct {
+	commands int
+}
+
+func (c *countingClient) GenerateCommand(ctx context.Context, query string, sysCtx *contextPkg.SystemContext) (string, error) {
+	c.commands++
+	return "make test", nil
+}
+
+func (c *countingClient) ExplainCommand(ctx context.Context, command string, sysCtx *contextPkg.SystemContext) (*llm.Explanation, error) {
+	return &llm.Explanation{Summary: "Runs the tests"}, nil
+}
+
+func (c *countingClient) GeneratePlan(ctx context.Context, query string, sysCtx *contextPkg.SystemContext) (*llm.Plan, error) {
+	return &llm.Plan{}, nil
+}
+
+func TestForgetCachedAnswer_AfterExplanation(t *testing.T) {
+	cfg := config.Default()
+	display := newJSONDisplay()
+	inner := &countingClient{}
+	client := llm.NewCachingClient(inner, t.TempDir(), "ollama", "qwen2.5-coder:7b", time.Hour, 10)
+	sysCtx := &contextPkg.SystemContext{}
+
+	generateCommand(cfg, display, client, "run the tests", sysCtx)
+	answer := cachedAnswer(client)
+	showExplanation(cfg, display, client, redact.New(), "make test") // --explain
+	forgetCachedAnswer(client, answer)                               // make test failed
+
+	generateCommand(cfg, display, client, "run the tests", sysCtx)
+	if inner.commands != 2 {
+		t.Errorf("Expected the failed command to be asked for again, got %d requests", inner.commands)
+	}
+}
+
+func TestRequests_TransportErrorIsNotCancellation(t *testing.T) {
+	cfg := config.Default()
+	display := newJSONDisplay()
+	transportErr := errors.New("dial tcp 127.0.0.1:11434: connect: connection refused")
+	client := &failingClient{err: transportErr}
+	sysCtx := &contextPkg.SystemContext{}
+
+	_, commandErr := generateCommand(cfg, display, client, "list files", sysCtx)
+	_, explainErr := explainCommand(cfg, display, client, redact.New(), "ls -la", false)
+	_, planErr := generatePlan(cfg, display, client, "build and test", sysCtx)
+
+	for name, err := range map[string]error{"generateCommand": commandErr, "explainCommand": explainErr, "generatePlan": planErr} {
+		if !errors.Is(err, transportErr) {
+			t.Errorf("%s: expected the transport error, got %v", name, err)
+		}
+	}
+}
+
+func TestCommandLine_SettingFlags(t *testing.T) {
+	if _, err := commandLine.Parse([]string{"--max-fix-rounds", "1", "list", "files"}); err != nil {
+		t.Errorf("Expected a setting flag to be accepted, got %v", err)
+	}
+
+	for _, flag := range []string{"--api-key=sk-ant-xxx", "--profiles={}", "--profile-rules=[]"} {
+		if _, err := commandLine.Parse([]string{flag, "list", "files"}); err == nil || !strings.Contains(err.Error(), "unknown flag") {
+			t.Errorf("Expected %s to be rejected, got %v", flag, err)
+		}
+	}
+}
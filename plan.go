@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/config"
+	contextPkg "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/redact"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+// runPlan handles `zchat --plan`: it asks for an ordered list of steps, safety-checks each
+// one independently and runs them in sequence, stopping at the first failure
+func runPlan(cfg *config.Config, display ui.Display, client llm.Client, redactor *redact.Redactor, rec *recorder, query string, sysCtx *contextPkg.SystemContext, stateDir string) {
+	plan, err := generatePlan(cfg, display, client, query, sysCtx)
+	if err != nil {
+		fail(display, "generating plan", err)
+	}
+	showCacheHit(display, client)
+	answer := cachedAnswer(client)
+
+	// Safety check every step on its own, so one harmless step can't vouch for the others
+	warnings := make([]string, len(plan.Steps))
+	for i, step := range plan.Steps {
+		checked, _ := checkedCommand(cfg, step.Command)
+		if isDangerous, reason := executor.IsDangerous(checked, cfg.DangerousPatterns); isDangerous {
+			warnings[i] = reason
+		}
+	}
+	display.ShowPlan(plan, warnings)
+	if cfg.Approve == config.ApproveNever {
+		return
+	}
+
+	mode := ui.PlanAll
+	if !planApproved(cfg, plan) {
+		if !canAsk(display, cfg) {
+			fmt.Fprintln(os.Stderr, "Refusing to run without a terminal to confirm on: not every step is read-only")
+			os.Exit(1)
+		}
+		mode, err = display.ConfirmPlan()
+		if err != nil || mode == ui.PlanCancel {
+			fmt.Println("Plan cancelled.")
+			os.Exit(0)
+		}
+	}
+
+	exec := newExecutor(cfg, sysCtx)
+	for i, step := range plan.Steps {
+		display.ShowPlanStep(i+1, len(plan.Steps), step)
+		entry := rec.newEntry(query, step.Command, sysCtx)
+
+		// Dangerous steps need explicit confirmation even when running all steps
+		confirmed := true
+		if mode == ui.PlanStepByStep {
+			confirmed = confirmCommand(display, cfg, step.Command)
+		} else if warnings[i] != "" {
+			confirmed = confirmDanger(display, cfg, warnings[i])
+		}
+		if !confirmed {
+			rec.save(entry)
+			fmt.Printf("Plan stopped before step %d.\n", i+1)
+			os.Exit(0)
+		}
+
+		output, err := executeRecorded(cfg, exec, step.Command, entry)
+		rec.save(entry)
+		display.ShowResult(output, err, time.Duration(entry.DurationMs)*time.Millisecond)
+		if err != nil {
+			forgetCachedAnswer(client, answer)
+
+			// Let `zchat fix` pick up the failed step
+			failure := executor.NewFailure(query, step.Command, output.Combined, err)
+			failure.Output = redactor.String(failure.Output)
+			if stateDir != "" {
+				executor.SaveFailure(stateDir, failure)
+			}
+
+			fmt.Printf("Plan stopped: step %d of %d failed.\n", i+1, len(plan.Steps))
+			os.Exit(1)
+		}
+	}
+
+	if stateDir != "" {
+		executor.ClearFailure(stateDir)
+	}
+
+	if !canAsk(display, cfg) {
+		return
+	}
+	path, err := display.PromptScriptPath()
+	if err != nil || path == "" {
+		return
+	}
+	if err := savePlanScript(path, plan.Script(query, sysCtx.Shell)); err != nil {
+		display.ShowError(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved plan to %s\n", path)
+}
+
+// generatePlan asks the model for the steps of a plan
+func generatePlan(cfg *config.Config, display ui.Display, client llm.Client, query string, sysCtx *contextPkg.SystemContext) (*llm.Plan, error) {
+	ctx, done := startRequest(display, cfg)
+	defer done()
+
+	plan, err := client.GeneratePlan(ctx, query, sysCtx)
+	return plan, requestError(ctx, err)
+}
+
+// planApproved reports whether the approval policy lets every step run without asking
+func planApproved(cfg *config.Config, plan *llm.Plan) bool {
+	for _, step := range plan.Steps {
+		if !approvedByPolicy(cfg, step.Command) {
+			return false
+		}
+	}
+	return true
+}
+
+// savePlanScript writes an executable script, refusing to overwrite an existing file
+func savePlanScript(path, script string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(script)
+	return err
+}
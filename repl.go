@@ -0,0 +1,308 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/config"
+	contextPkg "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/redact"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+const (
+	// maxSessionTurns bounds how many earlier requests are sent with each new one
+	maxSessionTurns = 10
+	// maxSessionChars bounds the size of the conversation section
+	maxSessionChars = 2000
+	// maxOutcomeChars bounds the error line kept for a failed command
+	maxOutcomeChars = 200
+)
+
+// turn is one request in an interactive session and what came of it
+type turn struct {
+	query   string
+	command string
+	outcome string // "not run", "exit 0" or "exit N: <last line of output>"
+}
+
+// replSession holds the state of an interactive session
+type replSession struct {
+	cfg       *config.Config
+	display   ui.Display
+	client    llm.Client
+	redactor  *redact.Redactor
+	collector *contextPkg.DefaultCollector
+	recorder  *recorder
+	sysCtx    *contextPkg.SystemContext
+	turns     []turn
+	verbose   bool
+}
+
+// runREPL handles `zchat` without a query: context is collected once and refreshed after each
+// executed command, and earlier requests are sent along so follow-ups can refer to them
+func runREPL(opts options) {
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	collector, err := buildCollector(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	llmClient, err := newClient(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	stateDir, _ := config.StateDir()
+	s := &replSession{
+		cfg:       cfg,
+		display:   ui.NewDisplay(),
+		client:    llmClient,
+		redactor:  redactor,
+		collector: collector,
+		recorder:  newRecorder(cfg, stateDir),
+		verbose:   cfg.Verbose,
+	}
+	setColor(s.display, cfg)
+	if err := s.refreshContext(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error collecting context: %v\n", err)
+		os.Exit(1)
+	}
+
+	historyPath := ""
+	if stateDir != "" {
+		historyPath = filepath.Join(stateDir, "repl_history")
+	}
+	editor := s.display.NewLineEditor(historyPath)
+
+	fmt.Printf("zchat interactive mode (%s, %s). Type /help for commands, Ctrl-D to exit.\n", cfg.Provider, cfg.Model)
+	for {
+		line, err := editor.ReadLine("zchat> ")
+		if errors.Is(err, ui.ErrInterrupted) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "/"):
+			if !s.slashCommand(line) {
+				return
+			}
+		default:
+			s.ask(line)
+		}
+	}
+}
+
+// refreshContext re-collects the system context, e.g. after a command changed the directory contents
+func (s *replSession) refreshContext() error {
+	sysCtx, err := s.collector.Collect()
+	if err != nil {
+		return err
+	}
+
+	findings := sysCtx.Redact(s.redactor)
+	if s.verbose {
+		s.display.ShowRedactions(findings)
+	}
+	s.sysCtx = sysCtx
+	return nil
+}
+
+// ask generates a command for query with the session's context and conversation, then runs it
+// through the usual safety check and confirmation
+func (s *replSession) ask(query string) {
+	promptCtx := *s.sysCtx
+	promptCtx.Sections = slices.Clone(s.sysCtx.Sections)
+	var snippets *contextPkg.Section
+	if !promptCtx.ContextOff {
+		if s.cfg.FilePreviewBytes > 0 {
+			promptCtx.Samples = contextPkg.NewFileSampler(s.cfg.FilePreviewBytes).Sample(query, promptCtx.WorkingDir)
+		}
+		snippets = snippetSection(query)
+	}
+	if snippets != nil {
+		promptCtx.Sections = append(promptCtx.Sections, *snippets)
+	}
+	if section := s.conversationSection(); section != nil {
+		promptCtx.Sections = append(promptCtx.Sections, *section)
+	}
+
+	findings := promptCtx.Redact(s.redactor)
+	query, queryFindings := s.redactor.Redact(query)
+	if s.verbose {
+		s.display.ShowRedactions(append(findings, queryFindings...))
+	}
+
+	command, err := generateCommand(s.cfg, s.display, s.client, query, &promptCtx)
+	if err != nil {
+		s.display.ShowError(err)
+		return
+	}
+	showCacheHit(s.display, s.client)
+	answer := cachedAnswer(s.client)
+	if snippets != nil {
+		if command, err = promptPlaceholders(s.display, command); err != nil {
+			return
+		}
+	}
+	s.display.ShowCommand(command, assessCommand(s.cfg, command))
+
+	t := turn{query: query, command: command, outcome: "not run"}
+	entry := s.recorder.newEntry(query, command, s.sysCtx)
+	if !confirmCommand(s.display, s.cfg, command) {
+		s.display.ShowCancelled()
+		s.turns = append(s.turns, t)
+		s.recorder.save(entry)
+		return
+	}
+
+	output, err := executeRecorded(s.cfg, newExecutor(s.cfg, s.sysCtx), command, entry)
+	s.recorder.save(entry)
+	s.display.ShowResult(output, err, time.Duration(entry.DurationMs)*time.Millisecond)
+	if err != nil {
+		forgetCachedAnswer(s.client, answer)
+		t.outcome = fmt.Sprintf("exit %d: %s", executor.ExitCode(err), lastLine(s.redactor.String(output.Combined)))
+	} else {
+		t.outcome = "exit 0"
+	}
+	s.turns = append(s.turns, t)
+
+	if err := s.refreshContext(); err != nil {
+		s.display.ShowError(err)
+	}
+}
+
+// conversationSection renders the most recent turns for the prompt
+func (s *replSession) conversationSection() *contextPkg.Section {
+	if len(s.turns) == 0 {
+		return nil
+	}
+
+	section := &contextPkg.Section{
+		Provider: "session",
+		Title:    "EARLIER IN THIS SESSION (oldest first; the new request may refer to these)",
+		KeepTail: true,
+	}
+	for _, t := range s.turns[max(0, len(s.turns)-maxSessionTurns):] {
+		section.Items = append(section.Items, fmt.Sprintf("request: %s -> command: %s (%s)", t.query, t.command, t.outcome))
+	}
+	section.Trim(maxSessionChars)
+
+	return section
+}
+
+// slashCommand handles a /command and reports whether the session should continue
+func (s *replSession) slashCommand(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/help":
+		fmt.Println("  /provider [name]   show or switch the provider (ollama, anthropic)")
+		fmt.Println("  /model [name]      show or switch the model")
+		fmt.Println("  /explain [command] explain a command, or the last generated one")
+		fmt.Println("  /undo              forget the last request so follow-ups don't refer to it")
+		fmt.Println("  /context           show the context sent with each request")
+		fmt.Println("  /exit              leave the session (or Ctrl-D)")
+	case "/exit", "/quit":
+		return false
+	case "/provider":
+		if arg != "" {
+			s.reconfigure(func(cfg *config.Config) { cfg.Provider = arg })
+		}
+		fmt.Printf("Provider: %s (model %s)\n", s.cfg.Provider, s.cfg.Model)
+	case "/model":
+		if arg != "" {
+			s.reconfigure(func(cfg *config.Config) { cfg.Model = arg })
+		}
+		fmt.Printf("Model: %s\n", s.cfg.Model)
+	case "/explain":
+		s.explain(arg)
+	case "/undo":
+		if len(s.turns) == 0 {
+			fmt.Println("Nothing to undo.")
+			break
+		}
+		last := s.turns[len(s.turns)-1]
+		s.turns = s.turns[:len(s.turns)-1]
+		fmt.Printf("Forgot: %s\n", last.query)
+	case "/context":
+		s.display.ShowContext(s.sysCtx)
+	default:
+		fmt.Printf("Unknown command: %s (type /help)\n", name)
+	}
+
+	return true
+}
+
+// reconfigure applies a change to the configuration and recreates the client, reverting if it's invalid
+func (s *replSession) reconfigure(change func(*config.Config)) {
+	previous := *s.cfg
+	change(s.cfg)
+
+	if err := s.cfg.Validate(); err != nil {
+		*s.cfg = previous
+		s.display.ShowError(err)
+		return
+	}
+	client, err := newClient(s.cfg)
+	if err != nil {
+		*s.cfg = previous
+		s.display.ShowError(err)
+		return
+	}
+	s.client = client
+}
+
+// explain shows a breakdown of command, defaulting to the last generated command
+func (s *replSession) explain(command string) {
+	if command == "" {
+		if len(s.turns) == 0 {
+			fmt.Println("Nothing to explain yet. Usage: /explain <command>")
+			return
+		}
+		command = s.turns[len(s.turns)-1].command
+	}
+
+	exp, err := explainCommand(s.cfg, s.display, s.client, s.redactor, command, s.verbose)
+	if err != nil {
+		s.display.ShowError(err)
+		return
+	}
+	showCacheHit(s.display, s.client)
+	s.display.ShowExplanation(exp)
+}
+
+// lastLine returns the last non-empty line of output, shortened
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	if len(line) > maxOutcomeChars {
+		line = line[:maxOutcomeChars] + "…"
+	}
+	return line
+}
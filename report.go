@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+var (
+	_ ui.Display  = (*jsonDisplay)(nil)
+	_ ui.Reporter = (*jsonDisplay)(nil)
+)
+
+// report is the single object JSON mode writes for a run
+type report struct {
+	Query       string           `json:"query"` // redacted: the query as sent to the model, with secrets masked
+	Provider    string           `json:"provider"`
+	Model       string           `json:"model"`
+	Command     string           `json:"command"`
+	Original    string           `json:"original,omitempty"` // the failed command a fix replaced
+	Cached      bool             `json:"cached"`
+	Explanation *llm.Explanation `json:"explanation,omitempty"`
+	Risk        *executor.Risk   `json:"risk,omitempty"`
+	Executed    bool             `json:"executed"`
+	ExitCode    *int             `json:"exit_code"` // null if the command didn't run
+	Stdout      string           `json:"stdout"`
+	Stderr      string           `json:"stderr"`
+	Error       string           `json:"error,omitempty"`
+	Timings     timings          `json:"timings"`
+}
+
+// timings break down where a run spent its time, in milliseconds
+type timings struct {
+	GenerateMs int64 `json:"generate_ms"` // until the command was ready, including context collection
+	ExecuteMs  int64 `json:"execute_ms"`
+	TotalMs    int64 `json:"total_ms"`
+}
+
+// jsonDisplay collects a run into a report and writes it to stdout on Flush. Prompts and
+// messages meant for a person still work, on stderr, so stdout holds only the JSON.
+type jsonDisplay struct {
+	*ui.TextDisplay
+	report report
+	start  time.Time
+	out    io.Writer
+}
+
+// newJSONDisplay creates a JSON display; its clock for the timings starts now
+func newJSONDisplay() *jsonDisplay {
+	text := ui.NewDisplay()
+	text.SetOutput(os.Stderr)
+
+	return &jsonDisplay{
+		TextDisplay: text,
+		start:       time.Now(),
+		out:         os.Stdout,
+	}
+}
+
+// ShowRequest records what was asked, already redacted, and of which model
+func (d *jsonDisplay) ShowRequest(query, provider, model string) {
+	d.report.Query = query
+	d.report.Provider = provider
+	d.report.Model = model
+}
+
+// ShowCommand records the generated command and its risk verdict
+func (d *jsonDisplay) ShowCommand(command string, risk executor.Risk) {
+	d.report.Command = command
+	d.report.Risk = &risk
+	if d.report.Timings.GenerateMs == 0 {
+		d.report.Timings.GenerateMs = time.Since(d.start).Milliseconds()
+	}
+}
+
+// ShowCommandDiff records a corrected command and the one it replaced
+func (d *jsonDisplay) ShowCommandDiff(original, command string, risk executor.Risk) {
+	d.report.Original = original
+	d.ShowCommand(command, risk)
+}
+
+// ShowCached records that the answer came from the response cache
+func (d *jsonDisplay) ShowCached(age time.Duration) {
+	d.report.Cached = true
+}
+
+// ShowWaiting shows nothing: JSON mode is for tools, which don't want a spinner
+func (d *jsonDisplay) ShowWaiting(label string) func() {
+	return func() {}
+}
+
+// ShowExplanation records a breakdown of the command
+func (d *jsonDisplay) ShowExplanation(exp *llm.Explanation) {
+	d.report.Explanation = exp
+}
+
+// ShowResult records how the command ran
+func (d *jsonDisplay) ShowResult(output *executor.Output, err error, duration time.Duration) {
+	exitCode := executor.ExitCode(err)
+	d.report.Executed = true
+	d.report.ExitCode = &exitCode
+	d.report.Stdout = output.Stdout
+	d.report.Stderr = output.Stderr
+	d.report.Timings.ExecuteMs = duration.Milliseconds()
+	if err != nil {
+		d.report.Error = err.Error()
+	}
+}
+
+// ShowSuccess records output of a command that succeeded
+func (d *jsonDisplay) ShowSuccess(output string) {
+	d.report.Stdout = output
+}
+
+// ShowError records an error; the last one wins
+func (d *jsonDisplay) ShowError(err error) {
+	d.report.Error = err.Error()
+}
+
+// Flush writes the report as one JSON object
+func (d *jsonDisplay) Flush() error {
+	d.report.Timings.TotalMs = time.Since(d.start).Milliseconds()
+
+	encoder := json.NewEncoder(d.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d.report)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	contextPkg "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/executor"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+func TestJSONDisplay(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	text := &ui.TextDisplay{}
+	text.SetOutput(&stderr)
+	display := &jsonDisplay{TextDisplay: text, start: time.Now(), out: &stdout}
+
+	display.ShowRequest("count lines", "ollama", "qwen2.5-coder:7b")
+	display.ShowCommandDiff("wc -l data.cvs", "wc -l data.csv", executor.Risk{Level: executor.RiskLow, Reasons: []string{"Command only reads files or state"}})
+	display.ShowCached(time.Minute)
+	display.ShowExplanation(&llm.Explanation{Command: "wc -l data.csv", Summary: "Counts lines"})
+	display.ShowResult(&executor.Output{Combined: "3 data.csv\n", Stdout: "3 data.csv\n"}, nil, 12*time.Millisecond)
+	display.ShowContext(&contextPkg.SystemContext{Shell: "/bin/zsh", OS: "linux", Arch: "amd64"})
+	if err := display.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	var result report
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("Expected a single JSON object, got %v:\n%s", err, stdout.String())
+	}
+
+	if result.Query != "count lines" || result.Provider != "ollama" || result.Model != "qwen2.5-coder:7b" {
+		t.Errorf("Unexpected request fields: %+v", result)
+	}
+	if result.Command != "wc -l data.csv" || result.Original != "wc -l data.cvs" {
+		t.Errorf("Unexpected command fields: %+v", result)
+	}
+	if result.Risk == nil || result.Risk.Level != executor.RiskLow {
+		t.Errorf("Expected low risk, got %+v", result.Risk)
+	}
+	if !result.Cached {
+		t.Error("Expected cached to be true")
+	}
+	if result.Explanation == nil || result.Explanation.Summary != "Counts lines" {
+		t.Errorf("Expected explanation, got %+v", result.Explanation)
+	}
+	if !result.Executed || result.ExitCode == nil || *result.ExitCode != 0 {
+		t.Errorf("Expected executed with exit code 0, got %+v", result)
+	}
+	if result.Stdout != "3 data.csv\n" || result.Stderr != "" {
+		t.Errorf("Unexpected output: %q / %q", result.Stdout, result.Stderr)
+	}
+	if result.Timings.ExecuteMs != 12 {
+		t.Errorf("Expected execute_ms 12, got %d", result.Timings.ExecuteMs)
+	}
+
+	// Text meant for a person goes to stderr, never into the JSON
+	if !strings.Contains(stderr.String(), "Shell:") {
+		t.Errorf("Expected context on stderr, got '%s'", stderr.String())
+	}
+}
+
+func TestJSONDisplay_NotExecuted(t *testing.T) {
+	var stdout bytes.Buffer
+	text := &ui.TextDisplay{}
+	text.SetOutput(&bytes.Buffer{})
+	display := &jsonDisplay{TextDisplay: text, start: time.Now(), out: &stdout}
+
+	display.ShowCommand("rm -rf build", executor.Risk{Level: executor.RiskHigh})
+	display.ShowError(errors.New("refused"))
+	display.Flush()
+
+	var raw map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if raw["executed"] != false || raw["exit_code"] != nil {
+		t.Errorf("Expected executed false and exit_code null, got %v / %v", raw["executed"], raw["exit_code"])
+	}
+	if _, ok := raw["query"]; !ok {
+		t.Errorf("Expected a query field, got %v", raw)
+	}
+	if raw["error"] != "refused" {
+		t.Errorf("Expected error 'refused', got %v", raw["error"])
+	}
+}
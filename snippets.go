@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/palaforcade/zchat/internal/config"
+	contextPkg "github.com/palaforcade/zchat/internal/context"
+	"github.com/palaforcade/zchat/internal/history"
+	"github.com/palaforcade/zchat/internal/llm"
+	"github.com/palaforcade/zchat/internal/snippet"
+	"github.com/palaforcade/zchat/internal/ui"
+)
+
+// runSave handles `zchat save <name> [param=value ...]`: the last command that succeeded is
+// stored as a snippet, with each given value turned into a {{param}} placeholder
+func runSave(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: zchat save <name> [param=value ...]")
+		os.Exit(1)
+	}
+	name := args[0]
+	values, rest := snippet.ParseArgs(args[1:])
+	if len(rest) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: expected param=value, got %q\n", rest[0])
+		os.Exit(1)
+	}
+
+	stateDir, err := config.StateDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	entries, err := history.NewStore(stateDir).List(0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+		os.Exit(1)
+	}
+	var last *history.Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Executed && entries[i].ExitCode == 0 {
+			last = &entries[i]
+			break
+		}
+	}
+	if last == nil {
+		fmt.Fprintln(os.Stderr, "Error: no successful command in the history to save")
+		os.Exit(1)
+	}
+
+	command, err := snippet.Parameterize(last.Command, values)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	lib, err := snippet.Load(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := lib.Put(snippet.Snippet{Name: name, Command: command, Description: last.Query}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := lib.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving snippet: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved %s: %s\n", name, command)
+}
+
+// runSnippet handles `zchat run <name> [param=value ...] [request]`. Placeholders without a
+// value are filled by the model from the request if there is one, otherwise the user is asked.
+// Without a name it lists the saved snippets.
+func runSnippet(args []string, opts options) {
+	lib := loadSnippets()
+	display := ui.NewDisplay()
+	if len(args) < 1 {
+		display.ShowSnippets(lib.Snippets)
+		return
+	}
+
+	s, err := lib.Get(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	values, rest := snippet.ParseArgs(args[1:])
+	command, missing := snippet.Fill(s.Command, values)
+
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	collector, err := buildCollector(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	sysCtx, err := collector.Collect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error collecting context: %v\n", err)
+		os.Exit(1)
+	}
+
+	setColor(display, cfg)
+
+	if len(missing) > 0 && len(rest) > 0 {
+		command = fillWithModel(cfg, display, sysCtx, command, missing, strings.Join(rest, " "))
+	} else if command, err = promptPlaceholders(display, command); err != nil {
+		display.ShowCancelled()
+		os.Exit(0)
+	}
+	display.ShowCommand(command, assessCommand(cfg, command))
+
+	stateDir, _ := config.StateDir()
+	rec := newRecorder(cfg, stateDir)
+	entry := rec.newEntry(strings.Join(append([]string{"run"}, args...), " "), command, sysCtx)
+
+	if !confirmCommand(display, cfg, command) {
+		rec.save(entry)
+		exitNotRun(display, cfg)
+	}
+
+	output, err := executeRecorded(cfg, newExecutor(cfg, sysCtx), command, entry)
+	rec.save(entry)
+	display.ShowResult(output, err, time.Duration(entry.DurationMs)*time.Millisecond)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// fillWithModel asks the model to fill the snippet's missing placeholders from request
+func fillWithModel(cfg *config.Config, display ui.Display, sysCtx *contextPkg.SystemContext, command string, missing []string, request string) string {
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	findings := sysCtx.Redact(redactor)
+	query, queryFindings := redactor.Redact(llm.BuildSnippetQuery(command, missing, request))
+	if cfg.Verbose {
+		display.ShowRedactions(append(findings, queryFindings...))
+	}
+
+	llmClient, err := newClient(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	filled, err := generateCommand(cfg, display, llmClient, query, sysCtx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating command: %v\n", err)
+		os.Exit(1)
+	}
+	showCacheHit(display, llmClient)
+
+	// Anything the model left open is still asked for
+	filled, err = promptPlaceholders(display, filled)
+	if err != nil {
+		display.ShowCancelled()
+		os.Exit(0)
+	}
+	return filled
+}
+
+// promptPlaceholders asks the user for each {{placeholder}} left in command
+func promptPlaceholders(display ui.Display, command string) (string, error) {
+	values := map[string]string{}
+	for _, name := range snippet.Placeholders(command) {
+		value, err := display.PromptValue(name)
+		if err != nil {
+			return "", err
+		}
+		values[name] = value
+	}
+	command, _ = snippet.Fill(command, values)
+	return command, nil
+}
+
+// isSaveCommand reports whether args look like "<name> [param=value ...]" rather than a query
+func isSaveCommand(args []string) bool {
+	if len(args) == 0 || !snippet.ValidName(args[0]) {
+		return false
+	}
+	_, rest := snippet.ParseArgs(args[1:])
+	return len(rest) == 0
+}
+
+// hasSnippet reports whether a snippet called name is saved
+func hasSnippet(name string) bool {
+	_, err := loadSnippets().Get(name)
+	return err == nil
+}
+
+// loadSnippets reads the snippets file next to config.yaml. A broken file is reported and
+// treated as empty, so it never blocks ordinary queries.
+func loadSnippets() *snippet.Library {
+	dir, err := config.Dir()
+	if err != nil {
+		return &snippet.Library{}
+	}
+	lib, err := snippet.Load(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return &snippet.Library{}
+	}
+	return lib
+}
+
+// snippetSection tells the model about saved snippets that look relevant to query
+func snippetSection(query string) *contextPkg.Section {
+	matches := loadSnippets().Match(query)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	section := &contextPkg.Section{
+		Provider: "snippets",
+		Title:    "SAVED SNIPPETS (the user's own commands; prefer one that fits, keeping {{placeholders}} you can't fill)",
+	}
+	for _, s := range matches {
+		item := fmt.Sprintf("%s: %s", s.Name, s.Command)
+		if s.Description != "" {
+			item += "  # " + s.Description
+		}
+		section.Items = append(section.Items, item)
+	}
+	return section
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// buildZchat compiles the zchat binary into a temporary directory
+func buildZchat(t *testing.T) string {
+	t.Helper()
+
+	binary := filepath.Join(t.TempDir(), "zchat")
+	build := exec.Command("go", "build", "-o", binary, "github.com/palaforcade/zchat")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build zchat: %v\n%s", err, output)
+	}
+	return binary
+}
+
+// fakeOllama answers every generate request with command and records the prompts it received
+func fakeOllama(t *testing.T, command string) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		prompts = append(prompts, req.Prompt)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"response": command, "done": true})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &prompts
+}
+
+// shellEnv isolates zchat's config, state and cache and points it at the fake server
+func shellEnv(t *testing.T, ollamaURL string) []string {
+	home := t.TempDir()
+	return append(os.Environ(),
+		"HOME="+home,
+		"XDG_STATE_HOME="+filepath.Join(home, "state"),
+		"XDG_CACHE_HOME="+filepath.Join(home, "cache"),
+		"ZCHAT_PROVIDER=ollama",
+		"OLLAMA_URL="+ollamaURL,
+	)
+}
+
+// initScript returns the output of `zchat init <shell>`
+func initScript(t *testing.T, binary, shell string) string {
+	t.Helper()
+
+	output, err := exec.Command(binary, "init", shell).Output()
+	if err != nil {
+		t.Fatalf("zchat init %s failed: %v", shell, err)
+	}
+	return string(output)
+}
+
+func TestPrintMode(t *testing.T) {
+	binary := buildZchat(t)
+	server, prompts := fakeOllama(t, "ls -la")
+
+	cmd := exec.Command(binary, "--print", "--", "explain", "the files")
+	cmd.Env = shellEnv(t, server.URL)
+	cmd.Dir = t.TempDir()
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("zchat --print failed: %v\n%s", err, stderr.String())
+	}
+
+	if string(output) != "ls -la\n" {
+		t.Errorf("Expected only the command on stdout, got %q", output)
+	}
+	if len(*prompts) != 1 || !strings.Contains((*prompts)[0], "User request: explain the files") {
+		t.Errorf("Expected -- to send 'explain' as part of the query, got %v", *prompts)
+	}
+}
+
+func TestBashIntegration(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	binary := buildZchat(t)
+	server, _ := fakeOllama(t, "ls -la")
+	script := initScript(t, binary, "bash")
+
+	// Call the widget the way readline would, with the line being edited in READLINE_LINE
+	cmd := exec.Command(bash, "--norc", "--noprofile", "-c", script+`
+READLINE_LINE="list all files"
+READLINE_POINT=4
+_zchat_widget
+printf '%s|%s' "$READLINE_LINE" "$READLINE_POINT"
+`)
+	cmd.Env = shellEnv(t, server.URL)
+	cmd.Dir = t.TempDir()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash failed: %v\n%s", err, output)
+	}
+	if string(output) != "ls -la|6" {
+		t.Errorf("Expected the line to be replaced with the cursor at the end, got %q", output)
+	}
+
+	// An empty line is left alone without asking the model
+	cmd = exec.Command(bash, "--norc", "--noprofile", "-c", script+`
+READLINE_LINE="   "
+_zchat_widget
+printf '[%s]' "$READLINE_LINE"
+`)
+	cmd.Env = shellEnv(t, "http://127.0.0.1:1")
+	output, err = cmd.CombinedOutput()
+	if err != nil || string(output) != "[   ]" {
+		t.Errorf("Expected empty line to be unchanged, got %q (%v)", output, err)
+	}
+
+	// The key binding only exists in interactive shells
+	cmd = exec.Command(bash, "--norc", "--noprofile", "-i", "-c", script+"bind -X")
+	cmd.Env = shellEnv(t, server.URL)
+	output, _ = cmd.CombinedOutput()
+	if !strings.Contains(string(output), `"\ez": "_zchat_widget"`) {
+		t.Errorf("Expected Alt-z to be bound, got:\n%s", output)
+	}
+}
+
+func TestFishIntegration(t *testing.T) {
+	fish, err := exec.LookPath("fish")
+	if err != nil {
+		t.Skip("fish not installed")
+	}
+	binary := buildZchat(t)
+	server, _ := fakeOllama(t, "ls -la")
+	script := initScript(t, binary, "fish")
+
+	// commandline only works in an interactive session, so stand in for it with a function
+	// that serves the request and records what the widget puts back
+	cmd := exec.Command(fish, "--no-config", "-c", script+`
+function commandline
+    switch "$argv[1]"
+        case -r
+            set -g replaced $argv[3..-1]
+        case -f
+        case '*'
+            echo "list all files"
+    end
+end
+_zchat_widget
+printf '%s' "$replaced"
+`)
+	cmd.Env = shellEnv(t, server.URL)
+	cmd.Dir = t.TempDir()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("fish failed: %v\n%s", err, output)
+	}
+	if string(output) != "ls -la" {
+		t.Errorf("Expected the command line to be replaced, got %q", output)
+	}
+}